@@ -0,0 +1,169 @@
+// cmd/server/commands.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// programName is how the binary refers to itself in help text
+const programName = "blog-server"
+
+// command is a subcommand, or a group of them such as "migrate"
+type command struct {
+	name        string
+	args        string        // Arguments shown after the name in usage
+	summary     string        // One line description for the command list
+	help        string        // Longer description for "help <command>"
+	flags       *flag.FlagSet // Nil when the command takes no flags
+	run         func(c *cli, args []string) error
+	subcommands []*command
+
+	// invalidConfig lets the command run when the configuration fails validation
+	invalidConfig bool
+}
+
+// commands is the command tree, in the order it is listed in help
+var commands = []*command{
+	serveCommand,
+	{
+		name:        "migrate",
+		summary:     "Manage database migrations",
+		subcommands: []*command{migrateUpCommand, migrateDownCommand, migrateStatusCommand},
+	},
+	{
+		name:        "user",
+		summary:     "Manage user accounts",
+		subcommands: []*command{userCreateCommand, userPasswdCommand, userReset2FACommand},
+	},
+	reindexCommand,
+	backupCommand,
+	restoreCommand,
+	exportCommand,
+	migrateMediaCommand,
+	configCommand,
+}
+
+// findCommand walks the command tree along args, returning the command they name
+// and the arguments left for it
+func findCommand(tree []*command, args []string) (*command, []string, error) {
+	var path []string
+	for {
+		var found *command
+		for _, cmd := range tree {
+			if len(args) > 0 && cmd.name == args[0] {
+				found = cmd
+				break
+			}
+		}
+		if found == nil {
+			if len(path) == 0 {
+				return nil, nil, fmt.Errorf("unknown command %q", args[0])
+			}
+			if len(args) == 0 {
+				return nil, nil, fmt.Errorf("%s needs a subcommand: %s", strings.Join(path, " "), commandNames(tree))
+			}
+			return nil, nil, fmt.Errorf("unknown command %q for %s, expected one of: %s", args[0], strings.Join(path, " "), commandNames(tree))
+		}
+
+		path = append(path, found.name)
+		args = args[1:]
+		if found.subcommands == nil {
+			return found, args, nil
+		}
+		tree = found.subcommands
+	}
+}
+
+func commandNames(tree []*command) string {
+	names := make([]string, len(tree))
+	for i, cmd := range tree {
+		names[i] = cmd.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// runHelp prints help for the command named in args, or the overview without one
+func runHelp(global *flag.FlagSet, args []string) int {
+	if len(args) == 0 {
+		printUsage(os.Stdout, global)
+		return exitOK
+	}
+
+	// A group on its own, like "help migrate", lists its subcommands
+	if len(args) == 1 {
+		for _, cmd := range commands {
+			if cmd.name == args[0] && cmd.subcommands != nil {
+				printCommandUsage(os.Stdout, cmd)
+				return exitOK
+			}
+		}
+	}
+
+	cmd, _, err := findCommand(commands, args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\nRun '%s help' for usage.\n", err, programName)
+		return exitUsage
+	}
+	printCommandUsage(os.Stdout, cmd)
+	return exitOK
+}
+
+// printUsage prints the overview of every command
+func printUsage(w io.Writer, global *flag.FlagSet) {
+	fmt.Fprintf(w, "Usage: %s [flags] <command> [arguments]\n\n", programName)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		if cmd.subcommands == nil {
+			fmt.Fprintf(w, "  %-18s %s\n", cmd.name, cmd.summary)
+			continue
+		}
+		for _, sub := range cmd.subcommands {
+			fmt.Fprintf(w, "  %-18s %s\n", cmd.name+" "+sub.name, sub.summary)
+		}
+	}
+	fmt.Fprintf(w, "  %-18s %s\n", "help [command]", "Show help for a command")
+
+	fmt.Fprintln(w, "\nFlags:")
+	global.SetOutput(w)
+	global.PrintDefaults()
+
+	fmt.Fprintf(w, "\nWithout a command the server is started. Exit status is %d on success, %d if the\n", exitOK, exitError)
+	fmt.Fprintf(w, "command fails and %d if the command line is invalid.\n", exitUsage)
+}
+
+// printCommandUsage prints a command's usage, description and flags
+func printCommandUsage(w io.Writer, cmd *command) {
+	name := cmd.name
+	for _, group := range commands {
+		for _, sub := range group.subcommands {
+			if sub == cmd {
+				name = group.name + " " + cmd.name
+			}
+		}
+	}
+
+	if cmd.subcommands != nil {
+		fmt.Fprintf(w, "Usage: %s %s <command>\n\n%s\n\nCommands:\n", programName, name, cmd.summary)
+		for _, sub := range cmd.subcommands {
+			fmt.Fprintf(w, "  %-10s %s\n", sub.name, sub.summary)
+		}
+		return
+	}
+
+	usage := strings.TrimSpace(name + " " + cmd.args)
+	fmt.Fprintf(w, "Usage: %s %s\n\n", programName, usage)
+	if cmd.help != "" {
+		fmt.Fprintln(w, cmd.help)
+	} else {
+		fmt.Fprintln(w, cmd.summary)
+	}
+	if cmd.flags != nil {
+		fmt.Fprintln(w, "\nFlags:")
+		cmd.flags.SetOutput(w)
+		cmd.flags.PrintDefaults()
+	}
+}
@@ -0,0 +1,30 @@
+// cmd/server/config.go
+package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+var configCommand = &command{
+	name:    "config",
+	summary: "Print the effective configuration with secrets redacted",
+	help: `Prints the configuration the other commands would run with, after defaults, the
+configuration file and environment variables are applied, as JSON. Passwords and
+secrets are redacted. Exits with an error if the configuration is invalid.`,
+	run:           runConfig,
+	invalidConfig: true,
+}
+
+func runConfig(c *cli, args []string) error {
+	if len(args) > 0 {
+		return usageError{"config takes no arguments"}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(c.cfg.Redacted()); err != nil {
+		return err
+	}
+	return c.cfg.Validate()
+}
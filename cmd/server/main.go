@@ -2,111 +2,172 @@
 package main
 
 import (
+	"blog-portfolio/internal/backup"
 	"blog-portfolio/internal/config"
 	"blog-portfolio/internal/database"
-	"blog-portfolio/internal/handlers"
 	"blog-portfolio/internal/logger"
 	"blog-portfolio/internal/repository"
-	"blog-portfolio/internal/router"
 	"blog-portfolio/internal/service"
-	"context"
+	"blog-portfolio/internal/storage"
+	"blog-portfolio/internal/utils"
+	"errors"
+	"flag"
 	"fmt"
-	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
+)
+
+// Exit codes shared by every command
+const (
+	exitOK    = 0
+	exitError = 1 // The command failed
+	exitUsage = 2 // The command line was invalid
 )
 
 func main() {
-	// Initialize logger
-	log := logger.New()
+	os.Exit(run(os.Args[1:]))
+}
 
-	// Load configuration
-	env := os.Getenv("ENVIRONMENT")
-	if env == "" {
-		env = "development"
+// run parses the global flags and runs the command that follows them. Without a
+// command the server is started.
+func run(args []string) int {
+	global := flag.NewFlagSet(programName, flag.ContinueOnError)
+	configFile := global.String("config", os.Getenv("CONFIG_FILE"), "configuration `file` (default config/<env>.json)")
+	environment := global.String("env", envOr("ENVIRONMENT", "development"), "`name` of the environment to run in")
+	global.Usage = func() { printUsage(os.Stderr, global) }
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
 	}
 
-	cfg, err := config.LoadConfig(env)
-	if err != nil {
-		log.Error("Failed to load configuration:", err)
-		os.Exit(1)
+	args = global.Args()
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+	if args[0] == "help" {
+		return runHelp(global, args[1:])
 	}
 
-	// Initialize database
-	db, err := database.New(log)
+	cmd, rest, err := findCommand(commands, args)
 	if err != nil {
-		log.Error("Failed to initialize database:", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "%v\nRun '%s help' for usage.\n", err, programName)
+		return exitUsage
 	}
-	defer db.Close()
 
-	// Run migrations
-	if err := db.RunMigrations(); err != nil {
-		log.Error("Failed to run migrations:", err)
-		os.Exit(1)
+	if cmd.flags != nil {
+		cmd.flags.Usage = func() { printCommandUsage(os.Stderr, cmd) }
+		if err := cmd.flags.Parse(rest); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return exitOK
+			}
+			return exitUsage
+		}
+		rest = cmd.flags.Args()
 	}
 
-	// Initialize repositories
-	postRepo := repository.NewPostRepository(db.DB)
-	tagRepo := repository.NewTagRepository(db.DB) // New tag repository
+	log := logger.New()
+	cfg, err := config.LoadConfigFile(*configFile, *environment)
+	if err != nil {
+		log.Error("Failed to load configuration:", err)
+		return exitError
+	}
+	if !cmd.invalidConfig {
+		if err := cfg.Validate(); err != nil {
+			log.Error(err)
+			return exitError
+		}
+	}
 
-	// Initialize services
-	postService := service.NewPostService(postRepo)
-	tagService := service.NewTagService(tagRepo) // New tag service
-
-	// Initialize handlers
-	h := handlers.New(log, postService, tagService)
-
-	// Initialize router
-	r := router.New(log, cfg, h)
-
-	// Setup HTTP server
-	addr := fmt.Sprintf(":%s", cfg.Server.Port)
-	server := &http.Server{
-		Addr:         addr,
-		Handler:      r,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	var usage usageError
+	switch err := cmd.run(&cli{log: log, cfg: cfg}, rest); {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usage):
+		fmt.Fprintf(os.Stderr, "%v\n\n", err)
+		printCommandUsage(os.Stderr, cmd)
+		return exitUsage
+	default:
+		log.Error(err)
+		return exitError
 	}
+}
 
-	// Server run context
-	serverCtx, serverStopCtx := context.WithCancel(context.Background())
+// usageError reports a command line that doesn't match the command's usage
+type usageError struct {
+	msg string
+}
 
-	// Listen for syscall signals for process lifecycle management
-	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
-	go func() {
-		<-sig
+func (e usageError) Error() string {
+	return e.msg
+}
 
-		// Shutdown signal with grace period of 30 seconds
-		shutdownCtx, _ := context.WithTimeout(serverCtx, 30*time.Second)
+// cli is what every command is given to run with
+type cli struct {
+	log *logger.Logger
+	cfg *config.Config
+}
 
-		go func() {
-			<-shutdownCtx.Done()
-			if shutdownCtx.Err() == context.DeadlineExceeded {
-				log.Error("Graceful shutdown timed out... forcing exit.")
-			}
-		}()
+// app holds the database and the services built on it
+type app struct {
+	db        *database.Database
+	mediaRepo *repository.MediaRepository
+	posts     *service.PostService
+	tags      *service.TagService
+	previews  *service.PreviewService
+	media     *service.MediaService
+	users     *service.UserService
+	backups   *backup.Manager
+}
 
-		// Trigger graceful shutdown
-		err := server.Shutdown(shutdownCtx)
-		if err != nil {
-			log.Error(err)
-		}
-		serverStopCtx()
-	}()
-
-	// Start the server
-	log.Info("Server is running on http://localhost" + addr)
-	err = server.ListenAndServe()
-	if err != nil && err != http.ErrServerClosed {
-		log.Error("Server failed to start:", err)
-		os.Exit(1)
+// openDB opens the database without migrating it
+func (c *cli) openDB() (*database.Database, error) {
+	db, err := database.New(c.log, c.cfg.Database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+	return db, nil
+}
+
+// openApp opens the database and builds the services. Close the database when done.
+func (c *cli) openApp() (*app, error) {
+	db, err := c.openDB()
+	if err != nil {
+		return nil, err
 	}
 
-	// Wait for server context to be stopped
-	<-serverCtx.Done()
+	mediaStore, err := storage.New(c.cfg.Media)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize media storage: %w", err)
+	}
+
+	// Initialize repositories
+	postRepo := repository.NewPostRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	previewRepo := repository.NewPreviewRepository(db)
+	mediaRepo := repository.NewMediaRepository(db)
+	userRepo := repository.NewUserRepository(db)
+
+	// Initialize services
+	return &app{
+		db:        db,
+		mediaRepo: mediaRepo,
+		posts: service.NewPostService(postRepo, utils.TOCOptions{
+			MinLevel: c.cfg.Content.TOCMinLevel,
+			MaxLevel: c.cfg.Content.TOCMaxLevel,
+		}),
+		tags:     service.NewTagService(tagRepo),
+		previews: service.NewPreviewService(previewRepo, c.cfg.Auth.Secret, c.cfg.App.BaseURL),
+		media:    service.NewMediaService(mediaRepo, mediaStore, c.cfg.Media.MaxImagePixels()),
+		users:    service.NewUserService(userRepo),
+		backups:  backup.New(c.log, db, c.cfg.Backup),
+	}, nil
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
 }
@@ -0,0 +1,157 @@
+// cmd/server/migrate.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+var (
+	migrateUpFlags  = flag.NewFlagSet("migrate up", flag.ContinueOnError)
+	migrateUpDryRun = migrateUpFlags.Bool("dry-run", false, "check the pending migrations without applying them")
+)
+
+var migrateUpCommand = &command{
+	name:    "up",
+	args:    "[-dry-run]",
+	summary: "Apply pending migrations",
+	help: `Applies pending migrations in order, each in its own transaction. It refuses to
+run if a migration has been edited since it was applied. With -dry-run the
+migrations are applied in a transaction that is rolled back, so errors are
+reported without changing the database.`,
+	flags: migrateUpFlags,
+	run:   runMigrateUp,
+}
+
+var (
+	migrateDownFlags  = flag.NewFlagSet("migrate down", flag.ContinueOnError)
+	migrateDownSteps  = migrateDownFlags.Int("steps", 1, "number of migrations to roll back")
+	migrateDownDryRun = migrateDownFlags.Bool("dry-run", false, "check the rollback without changing the database")
+)
+
+var migrateDownCommand = &command{
+	name:    "down",
+	args:    "[-steps n] [-dry-run]",
+	summary: "Roll back the most recent migrations",
+	help:    "Rolls back the most recently applied migrations, newest first, each in its own transaction.",
+	flags:   migrateDownFlags,
+	run:     runMigrateDown,
+}
+
+var migrateStatusCommand = &command{
+	name:    "status",
+	summary: "List applied and pending migrations",
+	run:     runMigrateStatus,
+}
+
+func runMigrateUp(c *cli, args []string) error {
+	if len(args) > 0 {
+		return usageError{"migrate up takes no arguments"}
+	}
+
+	db, err := c.openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	applied, err := db.MigrateUp(*migrateUpDryRun)
+	if *migrateUpDryRun {
+		for _, migration := range applied {
+			fmt.Printf("Would apply %06d_%s\n", migration.Version, migration.Name)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(applied) == 0:
+		c.log.Info("Database is up to date")
+	case *migrateUpDryRun:
+		c.log.Info(fmt.Sprintf("Dry run: %d migration(s) would be applied", len(applied)))
+	default:
+		c.log.Info(fmt.Sprintf("Applied %d migration(s)", len(applied)))
+	}
+	return nil
+}
+
+func runMigrateDown(c *cli, args []string) error {
+	if len(args) > 0 {
+		return usageError{"migrate down takes no arguments"}
+	}
+	if *migrateDownSteps < 1 {
+		return usageError{"-steps must be at least 1"}
+	}
+
+	db, err := c.openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rolledBack, err := db.MigrateDown(*migrateDownSteps, *migrateDownDryRun)
+	if *migrateDownDryRun {
+		for _, migration := range rolledBack {
+			fmt.Printf("Would roll back %06d_%s\n", migration.Version, migration.Name)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if *migrateDownDryRun {
+		c.log.Info(fmt.Sprintf("Dry run: %d migration(s) would be rolled back", len(rolledBack)))
+	} else {
+		c.log.Info(fmt.Sprintf("Rolled back %d migration(s)", len(rolledBack)))
+	}
+	return nil
+}
+
+func runMigrateStatus(c *cli, args []string) error {
+	if len(args) > 0 {
+		return usageError{"migrate status takes no arguments"}
+	}
+
+	db, err := c.openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	pending, problems := 0, 0
+	for _, status := range statuses {
+		state, appliedAt := "pending", ""
+		if status.AppliedAt != nil {
+			state = "applied"
+			appliedAt = status.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		switch {
+		case status.Modified:
+			state = "modified since applied"
+			problems++
+		case status.Missing:
+			state = "applied, file missing"
+			problems++
+		case status.AppliedAt == nil:
+			pending++
+		}
+		fmt.Fprintf(w, "%06d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d migration(s), %d pending\n", len(statuses), pending)
+	if problems > 0 {
+		return fmt.Errorf("%d applied migration(s) no longer match their files", problems)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// cmd/server/serve.go
+package main
+
+import (
+	"blog-portfolio/internal/database"
+	"blog-portfolio/internal/handlers"
+	"blog-portfolio/internal/jobs"
+	"blog-portfolio/internal/ogimage"
+	"blog-portfolio/internal/router"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+var serveCommand = &command{
+	name:    "serve",
+	summary: "Apply pending migrations and start the web server",
+	help: `Applies any pending migrations, then serves the site until interrupted. Scheduled
+posts are published, the trash is purged and the database is backed up in the
+background while it runs.`,
+	run: runServe,
+}
+
+func runServe(c *cli, args []string) error {
+	if len(args) > 0 {
+		return usageError{"serve takes no arguments"}
+	}
+	log, cfg := c.log, c.cfg
+
+	a, err := c.openApp()
+	if err != nil {
+		return err
+	}
+	defer a.db.Close()
+
+	// Run migrations
+	if err := a.db.RunMigrations(); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	h, err := c.handlers(a)
+	if err != nil {
+		return err
+	}
+
+	// Initialize router
+	r := router.New(log, cfg, h)
+
+	// Setup HTTP server
+	addr := fmt.Sprintf(":%s", cfg.Server.Port)
+	server := &http.Server{
+		Addr:         addr,
+		Handler:      r,
+		ReadTimeout:  cfg.Server.ReadTimeout(),
+		WriteTimeout: cfg.Server.WriteTimeout(),
+		IdleTimeout:  cfg.Server.IdleTimeout(),
+	}
+
+	// Server run context
+	serverCtx, serverStopCtx := context.WithCancel(context.Background())
+
+	// Publish scheduled posts in the background, catching up on any missed while down
+	go jobs.NewPublisher(log, a.posts).Run(serverCtx)
+
+	// Permanently delete posts that have outlived the trash retention period
+	go jobs.NewTrashPurger(log, a.posts, cfg.Content.TrashRetention()).Run(serverCtx)
+
+	// Snapshot the database on schedule; Postgres is backed up with its own tools
+	if a.db.Driver() == database.SQLite {
+		go jobs.NewBackupScheduler(log, a.backups, cfg.Backup.Interval()).Run(serverCtx)
+	}
+
+	// Listen for syscall signals for process lifecycle management
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	go func() {
+		<-sig
+
+		// Shutdown signal with the configured grace period
+		shutdownCtx, cancel := context.WithTimeout(serverCtx, cfg.Server.ShutdownTimeout())
+		defer cancel()
+
+		go func() {
+			<-shutdownCtx.Done()
+			if shutdownCtx.Err() == context.DeadlineExceeded {
+				log.Error("Graceful shutdown timed out... forcing exit.")
+			}
+		}()
+
+		// Trigger graceful shutdown
+		err := server.Shutdown(shutdownCtx)
+		if err != nil {
+			log.Error(err)
+		}
+		serverStopCtx()
+	}()
+
+	// Start the server
+	log.Info("Server is running on http://localhost" + addr)
+	err = server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		serverStopCtx()
+		return fmt.Errorf("server failed to start: %w", err)
+	}
+
+	// Wait for server context to be stopped
+	<-serverCtx.Done()
+	return nil
+}
+
+// handlers builds the HTTP handlers for the app's services
+func (c *cli) handlers(a *app) (*handlers.Handlers, error) {
+	// Share images for posts without a cover image
+	var ogImages *ogimage.Renderer
+	if c.cfg.OGImage.Enabled {
+		var err error
+		ogImages, err = ogimage.New(c.cfg.OGImage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize share images: %w", err)
+		}
+	}
+
+	return handlers.New(c.log, c.cfg, a.posts, a.tags, a.previews, a.media, a.users, a.backups, ogImages), nil
+}
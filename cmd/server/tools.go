@@ -0,0 +1,286 @@
+// cmd/server/tools.go
+package main
+
+import (
+	"blog-portfolio/internal/backup"
+	"blog-portfolio/internal/config"
+	"blog-portfolio/internal/database"
+	"blog-portfolio/internal/export"
+	"blog-portfolio/internal/logger"
+	"blog-portfolio/internal/repository"
+	"blog-portfolio/internal/router"
+	"blog-portfolio/internal/service"
+	"blog-portfolio/internal/storage"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+)
+
+var reindexCommand = &command{
+	name:    "reindex",
+	summary: "Re-render stored post HTML and rebuild the search index",
+	help: `Re-renders the stored HTML, table of contents and reading time of every post, then
+rebuilds the full-text search index. Run it after changing the Markdown renderer
+configuration or if search results have drifted from the posts.`,
+	run: runReindex,
+}
+
+var (
+	backupFlags = flag.NewFlagSet("backup", flag.ContinueOnError)
+	backupOut   = backupFlags.String("out", "", "write the snapshot to `file` instead of the backup directory, compressed if it ends in .gz")
+	backupList  = backupFlags.Bool("list", false, "list the snapshots in the backup directory instead")
+)
+
+var backupCommand = &command{
+	name:    "backup",
+	args:    "[-out file] [-list]",
+	summary: "Snapshot the database",
+	help: `Copies the database to a timestamped snapshot in backup.dir while the server keeps
+running, checks the copy's integrity and compresses it if backup.compress is set.
+Snapshots beyond the newest backup.keep are then deleted.`,
+	flags: backupFlags,
+	run:   runBackup,
+}
+
+var restoreCommand = &command{
+	name:    "restore",
+	args:    "<snapshot>",
+	summary: "Replace the database with a snapshot",
+	help: `Replaces the database with a snapshot, given by its name in the backup directory
+or by path. The snapshot is checked before it is swapped in and the current
+database is saved to a pre-restore snapshot first. Stop the server before
+restoring; pending migrations are applied when it next starts.`,
+	run: runRestore,
+}
+
+var (
+	exportFlags   = flag.NewFlagSet("export", flag.ContinueOnError)
+	exportOut     = exportFlags.String("out", "./public", "`directory` to write the site to")
+	exportBaseURL = exportFlags.String("base-url", "", "public `URL` the site will be hosted at (default app.base_url)")
+)
+
+var exportCommand = &command{
+	name:    "export",
+	args:    "[-out dir] [-base-url url]",
+	summary: "Render the public site to static files",
+	help: `Renders the home page, blog listings, posts, tag pages, feeds and sitemap into a
+directory that can be served by any static host. Files whose content hasn't
+changed are left alone, and files an earlier export wrote that the site no longer
+has, like the pages of unpublished posts, are removed. Other files in the directory
+are never touched. Search needs the server and is not exported.`,
+	flags: exportFlags,
+	run:   runExport,
+}
+
+var migrateMediaCommand = &command{
+	name:    "migrate-media",
+	args:    "<from> <to>",
+	summary: "Copy uploaded media between storage backends",
+	help: `Copies every uploaded file from one storage backend to another, where each is
+local or s3. Files already at the destination are skipped, so an interrupted copy
+can be run again. Switch media.storage in the configuration once it has finished.`,
+	run: runMigrateMedia,
+}
+
+func runReindex(c *cli, args []string) error {
+	if len(args) > 0 {
+		return usageError{"reindex takes no arguments"}
+	}
+
+	a, err := c.openApp()
+	if err != nil {
+		return err
+	}
+	defer a.db.Close()
+
+	ctx := context.Background()
+	count, err := a.posts.RerenderPosts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to re-render posts: %w", err)
+	}
+	c.log.Info("Re-rendered posts:", count)
+
+	indexed, err := a.posts.RebuildSearchIndex(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild search index: %w", err)
+	}
+	c.log.Info("Indexed posts for search:", indexed)
+	return nil
+}
+
+func runBackup(c *cli, args []string) error {
+	if len(args) > 0 {
+		return usageError{"backup takes no arguments"}
+	}
+	if *backupList && *backupOut != "" {
+		return usageError{"-list and -out can't be used together"}
+	}
+
+	if *backupList {
+		return listBackups(backup.New(c.log, nil, c.cfg.Backup))
+	}
+
+	db, err := c.openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	backups := backup.New(c.log, db, c.cfg.Backup)
+	if *backupOut != "" {
+		if err := backups.WriteFile(*backupOut); err != nil {
+			return err
+		}
+		c.log.Info("Backed up database to", *backupOut)
+		return nil
+	}
+
+	snapshot, err := backups.Create()
+	if err != nil {
+		return err
+	}
+	c.log.Info("Backed up database to", snapshot.Path)
+	return nil
+}
+
+// listBackups prints the snapshots in the backup directory, newest first
+func listBackups(backups *backup.Manager) error {
+	snapshots, err := backups.List()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTAKEN\tSIZE")
+	for _, snapshot := range snapshots {
+		fmt.Fprintf(w, "%s\t%s\t%d\n", snapshot.Name, snapshot.CreatedAt.Local().Format("2006-01-02 15:04:05"), snapshot.Size)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d snapshot(s)\n", len(snapshots))
+	return nil
+}
+
+func runRestore(c *cli, args []string) error {
+	if len(args) != 1 {
+		return usageError{"restore needs a snapshot name or path"}
+	}
+
+	db, err := c.openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if db.Driver() != database.SQLite {
+		return fmt.Errorf("restore needs SQLite; restore %s with its own tools", db.Driver())
+	}
+
+	backups := backup.New(c.log, db, c.cfg.Backup)
+	path := args[0]
+	if snapshot, err := backups.Find(path); err == nil {
+		path = snapshot.Path
+	} else if !errors.Is(err, backup.ErrNotFound) {
+		return err
+	} else if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("no snapshot %q in %s and no such file", args[0], c.cfg.Backup.Dir)
+	}
+
+	// Keep the current database outside the retention policy so the restore can be undone
+	previous := filepath.Join(c.cfg.Backup.Dir, "pre-restore-"+time.Now().UTC().Format("20060102-150405")+".db")
+	if c.cfg.Backup.Compress {
+		previous += ".gz"
+	}
+	if err := backups.WriteFile(previous); err != nil {
+		return fmt.Errorf("failed to save the current database: %w", err)
+	}
+	c.log.Info("Saved the current database to", previous)
+
+	dbPath := db.Path()
+	if err := db.Close(); err != nil {
+		return err
+	}
+	if err := backup.Restore(dbPath, path); err != nil {
+		return err
+	}
+	c.log.Info("Restored database from", path)
+	return nil
+}
+
+func runExport(c *cli, args []string) error {
+	if len(args) > 0 {
+		return usageError{"export takes no arguments"}
+	}
+
+	// Absolute links, feeds and the sitemap point at the static host
+	if *exportBaseURL != "" {
+		c.cfg.App.BaseURL = *exportBaseURL
+	}
+
+	a, err := c.openApp()
+	if err != nil {
+		return err
+	}
+	defer a.db.Close()
+
+	h, err := c.handlers(a)
+	if err != nil {
+		return err
+	}
+
+	exporter, err := export.New(c.log, router.New(c.log, c.cfg, h), a.posts, a.tags, a.media, export.Options{
+		OutDir:    *exportOut,
+		StaticDir: "web/static",
+		BaseURL:   c.cfg.App.BaseURL,
+		OGImages:  c.cfg.OGImage.Enabled,
+	})
+	if err != nil {
+		return usageError{err.Error()}
+	}
+
+	result, err := exporter.Run(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to export site: %w", err)
+	}
+	c.log.Info(fmt.Sprintf("Exported site to %s: %d files written, %d unchanged, %d removed, %d media files copied", *exportOut, result.Written, result.Unchanged, result.Removed, result.Media))
+	return nil
+}
+
+func runMigrateMedia(c *cli, args []string) error {
+	if len(args) != 2 {
+		return usageError{"migrate-media needs a source and a destination backend"}
+	}
+
+	a, err := c.openApp()
+	if err != nil {
+		return err
+	}
+	defer a.db.Close()
+
+	return migrateMedia(context.Background(), c.log, a.mediaRepo, c.cfg.Media, args[0], args[1])
+}
+
+// migrateMedia copies every uploaded file from one storage backend to another. Switch
+// media.storage in the configuration once it has finished.
+func migrateMedia(ctx context.Context, log *logger.Logger, repo *repository.MediaRepository, cfg config.MediaConfig, from, to string) error {
+	if from == to {
+		return fmt.Errorf("source and destination are both %q", from)
+	}
+
+	src, err := storage.NewBackend(from, cfg)
+	if err != nil {
+		return err
+	}
+	dst, err := storage.NewBackend(to, cfg)
+	if err != nil {
+		return err
+	}
+
+	copied, skipped, err := service.NewMediaService(repo, src, cfg.MaxImagePixels()).CopyFiles(ctx, dst)
+	log.Info(fmt.Sprintf("Copied %d media files from %s to %s, skipped %d already there", copied, from, to, skipped))
+	return err
+}
@@ -0,0 +1,161 @@
+// cmd/server/user.go
+package main
+
+import (
+	"blog-portfolio/internal/models"
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+var (
+	userCreateFlags = flag.NewFlagSet("user create", flag.ContinueOnError)
+	userCreateRole  = userCreateFlags.String("role", models.RoleAdmin, "role of the new user: admin, editor, author or contributor")
+)
+
+var userCreateCommand = &command{
+	name:    "create",
+	args:    "[-role role] <username>",
+	summary: "Create a user",
+	help: `Creates an account, an admin unless -role says otherwise, asking for its password
+twice. When standard input isn't a terminal the password is read from its first
+line instead, for scripts. The first admin is credited with any posts that have no
+author, as with /setup.`,
+	flags: userCreateFlags,
+	run:   runUserCreate,
+}
+
+var userPasswdCommand = &command{
+	name:    "passwd",
+	args:    "<username>",
+	summary: "Change a user's password",
+	help: `Sets a new password for an account without asking for the old one, for when it has
+been forgotten. The password is read as for user create.`,
+	run: runUserPasswd,
+}
+
+var userReset2FACommand = &command{
+	name:    "reset-2fa",
+	args:    "<username>",
+	summary: "Turn off a user's two-factor authentication",
+	help: `Turns off two-factor authentication for an account and deletes its recovery codes,
+for when its authenticator app and recovery codes are both lost. The user signs in
+with their password alone and can set it up again.`,
+	run: runUserReset2FA,
+}
+
+func runUserCreate(c *cli, args []string) error {
+	if len(args) != 1 {
+		return usageError{"user create needs a username"}
+	}
+
+	a, err := c.openApp()
+	if err != nil {
+		return err
+	}
+	defer a.db.Close()
+
+	password, err := readPassword()
+	if err != nil {
+		return err
+	}
+
+	// The first admin takes over the posts written before accounts existed
+	ctx := context.Background()
+	first := false
+	if *userCreateRole == models.RoleAdmin {
+		if first, err = a.users.NeedsSetup(ctx); err != nil {
+			return err
+		}
+	}
+
+	var user *models.User
+	if first {
+		user, err = a.users.CreateFirstAdmin(ctx, args[0], password)
+	} else {
+		user, err = a.users.CreateUser(ctx, args[0], password, *userCreateRole)
+	}
+	if err != nil {
+		return err
+	}
+	c.log.Info("Created", user.Role, "user", user.Username)
+	return nil
+}
+
+func runUserPasswd(c *cli, args []string) error {
+	if len(args) != 1 {
+		return usageError{"user passwd needs a username"}
+	}
+
+	a, err := c.openApp()
+	if err != nil {
+		return err
+	}
+	defer a.db.Close()
+
+	password, err := readPassword()
+	if err != nil {
+		return err
+	}
+
+	if err := a.users.ResetPassword(context.Background(), args[0], password); err != nil {
+		return err
+	}
+	c.log.Info("Changed the password of", args[0])
+	return nil
+}
+
+func runUserReset2FA(c *cli, args []string) error {
+	if len(args) != 1 {
+		return usageError{"user reset-2fa needs a username"}
+	}
+
+	a, err := c.openApp()
+	if err != nil {
+		return err
+	}
+	defer a.db.Close()
+
+	if err := a.users.ResetTOTP(context.Background(), args[0]); err != nil {
+		return err
+	}
+	c.log.Info("Turned off two-factor authentication of", args[0])
+	return nil
+}
+
+// readPassword prompts for a new password twice without echoing it, or reads the
+// first line of standard input when it isn't a terminal
+func readPassword() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", errors.New("no password on standard input")
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(os.Stderr, "Confirm password: ")
+	confirm, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+
+	if string(password) != string(confirm) {
+		return "", errors.New("the passwords don't match")
+	}
+	return string(password), nil
+}
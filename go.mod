@@ -1,6 +1,6 @@
 module blog-portfolio
 
-go 1.21.0
+go 1.23.0
 
 require (
 	github.com/go-chi/chi/v5 v5.2.0
@@ -9,8 +9,36 @@ require (
 
 require (
 	github.com/a-h/templ v0.2.793
+	github.com/alecthomas/chroma/v2 v2.14.0
+	github.com/chai2010/webp v1.4.0
+	github.com/fogleman/gg v1.3.0
 	github.com/golang-jwt/jwt/v5 v5.2.1
+	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0
+	github.com/gomarkdown/markdown v0.0.0-20241205020045-f7e15b2f3e62
+	github.com/gosimple/unidecode v1.0.1
+	github.com/lib/pq v1.10.9
 	github.com/mattn/go-sqlite3 v1.14.24
+	github.com/minio/minio-go/v7 v7.0.90
+	github.com/pquerna/otp v1.5.0
+	golang.org/x/crypto v0.36.0
+	golang.org/x/image v0.18.0
+	golang.org/x/term v0.30.0
 )
 
-require github.com/gomarkdown/markdown v0.0.0-20241205020045-f7e15b2f3e62 // indirect
+require (
+	github.com/boombuler/barcode v1.0.1-0.20190219062509-6c824513bacc // indirect
+	github.com/dlclark/regexp2 v1.11.0 // indirect
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/go-ini/ini v1.67.0 // indirect
+	github.com/goccy/go-json v0.10.5 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.18.0 // indirect
+	github.com/klauspost/cpuid/v2 v2.2.10 // indirect
+	github.com/minio/crc64nvme v1.0.1 // indirect
+	github.com/minio/md5-simd v1.1.2 // indirect
+	github.com/rs/xid v1.6.0 // indirect
+	golang.org/x/net v0.38.0 // indirect
+	golang.org/x/sys v0.31.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,308 @@
+// internal/backup/backup.go
+package backup
+
+import (
+	"blog-portfolio/internal/config"
+	"blog-portfolio/internal/database"
+	"blog-portfolio/internal/logger"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned for a snapshot name that isn't in the backup directory
+var ErrNotFound = errors.New("snapshot not found")
+
+// timeFormat is the UTC timestamp in snapshot names, which sort in time order
+const timeFormat = "20060102-150405"
+
+// snapshotPattern matches snapshot names such as blog-20240131-020000.db.gz
+var snapshotPattern = regexp.MustCompile(`^blog-(\d{8}-\d{6})\.db(\.gz)?$`)
+
+// Snapshot is a backup of the database in the backup directory
+type Snapshot struct {
+	Name      string
+	Path      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// Compressed reports whether the snapshot is gzipped
+func (s Snapshot) Compressed() bool {
+	return strings.HasSuffix(s.Name, ".gz")
+}
+
+// Manager takes verified snapshots of the database and enforces the retention policy
+type Manager struct {
+	logger *logger.Logger
+	db     *database.Database
+	cfg    config.BackupConfig
+	mu     sync.Mutex // Serializes snapshots taken on schedule, from the admin and the CLI
+}
+
+func New(logger *logger.Logger, db *database.Database, cfg config.BackupConfig) *Manager {
+	return &Manager{
+		logger: logger,
+		db:     db,
+		cfg:    cfg,
+	}
+}
+
+// Create writes a snapshot to the backup directory, checks its integrity and then
+// deletes the snapshots that fall outside the retention policy
+func (m *Manager) Create() (*Snapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(m.cfg.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %v", err)
+	}
+
+	now := time.Now().UTC()
+	name := "blog-" + now.Format(timeFormat) + ".db"
+	if m.cfg.Compress {
+		name += ".gz"
+	}
+	path := filepath.Join(m.cfg.Dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("snapshot %s already exists", name)
+	}
+
+	if err := m.write(path, m.cfg.Compress); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{Name: name, Path: path, Size: info.Size(), CreatedAt: now}
+
+	if _, err := m.prune(); err != nil {
+		m.logger.Error("Error pruning old backups:", err)
+	}
+
+	return snapshot, nil
+}
+
+// WriteFile writes a verified snapshot to path, outside the backup directory and its
+// retention policy. Paths ending in .gz are compressed.
+func (m *Manager) WriteFile(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %v", err)
+	}
+	return m.write(path, strings.HasSuffix(path, ".gz"))
+}
+
+// write copies the live database to a temporary file next to path with VACUUM INTO,
+// checks its integrity, then compresses or renames it into place
+func (m *Manager) write(path string, compress bool) error {
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	os.Remove(tmp)
+	defer os.Remove(tmp)
+
+	if err := m.db.Backup(tmp); err != nil {
+		return err
+	}
+	if err := database.CheckIntegrity(tmp); err != nil {
+		return fmt.Errorf("backup failed verification: %v", err)
+	}
+
+	if !compress {
+		return os.Rename(tmp, path)
+	}
+
+	gzTmp := tmp + ".gz"
+	defer os.Remove(gzTmp)
+	if err := compressFile(tmp, gzTmp); err != nil {
+		return fmt.Errorf("failed to compress backup: %v", err)
+	}
+	return os.Rename(gzTmp, path)
+}
+
+// List returns the snapshots in the backup directory, newest first
+func (m *Manager) List() ([]Snapshot, error) {
+	entries, err := os.ReadDir(m.cfg.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		match := snapshotPattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		createdAt, err := time.Parse(timeFormat, match[1])
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, Snapshot{
+			Name:      entry.Name(),
+			Path:      filepath.Join(m.cfg.Dir, entry.Name()),
+			Size:      info.Size(),
+			CreatedAt: createdAt,
+		})
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt) })
+
+	return snapshots, nil
+}
+
+// Find returns the snapshot with the given name
+func (m *Manager) Find(name string) (*Snapshot, error) {
+	if !snapshotPattern.MatchString(name) {
+		return nil, ErrNotFound
+	}
+
+	snapshots, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Name == name {
+			return &snapshot, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Latest returns the newest snapshot, or nil if there are none
+func (m *Manager) Latest() (*Snapshot, error) {
+	snapshots, err := m.List()
+	if err != nil || len(snapshots) == 0 {
+		return nil, err
+	}
+	return &snapshots[0], nil
+}
+
+// prune deletes the snapshots beyond the newest cfg.Keep and returns how many it removed
+func (m *Manager) prune() (int, error) {
+	if m.cfg.Keep <= 0 {
+		return 0, nil
+	}
+
+	snapshots, err := m.List()
+	if err != nil {
+		return 0, err
+	}
+	if len(snapshots) <= m.cfg.Keep {
+		return 0, nil
+	}
+
+	removed := 0
+	for _, snapshot := range snapshots[m.cfg.Keep:] {
+		if err := os.Remove(snapshot.Path); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	m.logger.Info("Deleted old backups:", removed)
+	return removed, nil
+}
+
+// Restore replaces the database file at dbPath with the snapshot at path, which may be
+// compressed. The snapshot is unpacked next to the database and checked before it is
+// renamed into place, so a bad snapshot never replaces a working database. Nothing may
+// have the database open while it runs.
+func Restore(dbPath, path string) error {
+	tmp := dbPath + ".restore"
+	os.Remove(tmp)
+	defer os.Remove(tmp)
+
+	if err := unpackFile(path, tmp); err != nil {
+		return fmt.Errorf("failed to read snapshot: %v", err)
+	}
+	if err := database.CheckIntegrity(tmp); err != nil {
+		return fmt.Errorf("snapshot failed verification: %v", err)
+	}
+
+	// Journals left by the old database would be replayed into the restored one
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(tmp, dbPath)
+}
+
+// compressFile gzips src into dst
+func compressFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// unpackFile copies src to dst, decompressing it if it is gzipped
+func unpackFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	var r io.Reader = in
+	if strings.HasSuffix(src, ".gz") {
+		zr, err := gzip.NewReader(in)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, r); err != nil {
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,141 @@
+// internal/config/validate.go
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// redacted replaces secrets in the printed configuration
+const redacted = "[redacted]"
+
+// minSecretLength is the shortest signing secret accepted in production
+const minSecretLength = 32
+
+// Validate reports every setting that is missing, out of range or unsafe for the
+// environment, so a misconfigured server refuses to start instead of failing later
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		add("server.port %q is not a valid port", c.Server.Port)
+	}
+	for _, timeout := range []struct {
+		name    string
+		seconds int
+	}{
+		{"read_timeout_seconds", c.Server.ReadTimeoutSeconds},
+		{"write_timeout_seconds", c.Server.WriteTimeoutSeconds},
+		{"idle_timeout_seconds", c.Server.IdleTimeoutSeconds},
+		{"request_timeout_seconds", c.Server.RequestTimeoutSeconds},
+		{"shutdown_timeout_seconds", c.Server.ShutdownTimeoutSeconds},
+	} {
+		if timeout.seconds <= 0 {
+			add("server.%s must be positive", timeout.name)
+		}
+	}
+
+	switch c.Database.Driver {
+	case "sqlite":
+		if c.Database.Path == "" {
+			add("database.path is required")
+		}
+	case "postgres":
+		if c.Database.Host == "" || c.Database.Name == "" {
+			add("database.host and database.name are required for postgres")
+		}
+	default:
+		add("database.driver %q is not supported", c.Database.Driver)
+	}
+	if c.Database.BusyTimeoutMS < 0 {
+		add("database.busy_timeout_ms can't be negative")
+	}
+	if c.Database.MaxOpenConns < 1 {
+		add("database.max_open_conns must be at least 1")
+	}
+
+	if c.Auth.Secret == "" {
+		add("auth.secret is required")
+	}
+	if c.Auth.SessionHours < 1 {
+		add("auth.session_hours must be at least 1")
+	}
+	if c.Auth.CookieName == "" {
+		add("auth.cookie_name is required")
+	}
+	switch strings.ToLower(c.Auth.CookieSameSite) {
+	case "strict", "lax":
+	case "none":
+		if !c.Auth.CookieSecure {
+			add("auth.cookie_same_site \"none\" needs auth.cookie_secure")
+		}
+	default:
+		add("auth.cookie_same_site %q must be strict, lax or none", c.Auth.CookieSameSite)
+	}
+
+	if c.Content.TOCMinLevel < 1 || c.Content.TOCMaxLevel > 6 || c.Content.TOCMinLevel > c.Content.TOCMaxLevel {
+		add("content.toc_min_level and toc_max_level must be between 1 and 6, min first")
+	}
+
+	switch c.Media.Storage {
+	case "local":
+		if c.Media.Dir == "" {
+			add("media.dir is required for local storage")
+		}
+	case "s3":
+		if c.Media.S3.Endpoint == "" || c.Media.S3.Bucket == "" {
+			add("media.s3.endpoint and media.s3.bucket are required for s3 storage")
+		}
+	default:
+		add("media.storage %q must be local or s3", c.Media.Storage)
+	}
+	if c.Media.MaxUploadMB < 1 {
+		add("media.max_upload_mb must be at least 1")
+	}
+	if c.Media.MaxImageMegapixels < 1 {
+		add("media.max_image_megapixels must be at least 1")
+	}
+
+	if c.Backup.Dir == "" {
+		add("backup.dir is required")
+	}
+	if c.Backup.IntervalHours < 0 || c.Backup.Keep < 0 {
+		add("backup.interval_hours and backup.keep can't be negative")
+	}
+
+	if c.Server.IsProduction() {
+		if c.Auth.Secret == defaultSecret {
+			add("auth.secret is still the development default; set JWT_SECRET")
+		} else if len(c.Auth.Secret) < minSecretLength {
+			add("auth.secret must be at least %d characters in production", minSecretLength)
+		}
+		if !c.Auth.CookieSecure {
+			add("auth.cookie_secure must be on in production")
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration:\n  " + strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// Redacted returns a copy of the configuration with passwords and secrets replaced,
+// safe to print or log
+func (c Config) Redacted() Config {
+	redact := func(s *string) {
+		if *s != "" {
+			*s = redacted
+		}
+	}
+
+	redact(&c.Auth.Secret)
+	redact(&c.Database.Password)
+	redact(&c.Media.S3.SecretKey)
+	return c
+}
@@ -2,50 +2,138 @@
 package database
 
 import (
+	"blog-portfolio/internal/config"
 	"blog-portfolio/internal/logger"
 	"database/sql"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// Drivers supported by New
+const (
+	SQLite   = "sqlite"
+	Postgres = "postgres"
+)
+
+// Database is a connection pool that accepts queries written with ? placeholders
+// for every driver, rewriting them into the driver's own form
 type Database struct {
 	*sql.DB
 	logger *logger.Logger
+	driver string
+	path   string // SQLite only
 }
 
-func New(logger *logger.Logger) (*Database, error) {
-	// Ensure database directory exists
-	dbDir := "./data"
-	if err := os.MkdirAll(dbDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create database directory: %v", err)
+func New(logger *logger.Logger, cfg config.DatabaseConfig) (*Database, error) {
+	var driverName string
+	switch cfg.Driver {
+	case SQLite:
+		// Ensure database directory exists
+		if err := os.MkdirAll(filepath.Dir(cfg.Path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %v", err)
+		}
+		driverName = "sqlite3"
+	case Postgres:
+		driverName = "postgres"
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
 	}
 
-	// Connect to SQLite database
-	dbPath := filepath.Join(dbDir, "blog.db")
-	db, err := sql.Open("sqlite3", dbPath+"?_foreign_keys=on")
+	db, err := sql.Open(driverName, cfg.DSN())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %v", err)
 	}
 
 	// Test the connection
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to connect to database: %v", err)
 	}
 
 	// Set connection pool settings
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(25)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
 
-	return &Database{
+	database := &Database{
 		DB:     db,
 		logger: logger,
-	}, nil
+		driver: cfg.Driver,
+	}
+	if cfg.Driver == SQLite {
+		database.path = cfg.Path
+	}
+	return database, nil
 }
 
 // Close closes the database connection
 func (db *Database) Close() error {
 	return db.DB.Close()
 }
+
+// Driver returns the database driver, SQLite or Postgres
+func (db *Database) Driver() string {
+	return db.driver
+}
+
+// Path returns the SQLite database file's path, or an empty string for Postgres
+func (db *Database) Path() string {
+	return db.path
+}
+
+// Backup writes a consistent copy of the database to path while it stays online.
+// Only SQLite databases can be backed up this way; use pg_dump for Postgres.
+func (db *Database) Backup(path string) error {
+	if db.driver != SQLite {
+		return fmt.Errorf("online backups need SQLite; back up %s with its own tools", db.driver)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %v", err)
+	}
+	if _, err := db.Exec("VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("failed to back up database: %v", err)
+	}
+	return nil
+}
+
+// CheckIntegrity runs SQLite's integrity check on the database file at path, opened
+// read-only, and returns an error describing any problems it finds
+func CheckIntegrity(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return fmt.Errorf("failed to check %s: %v", path, err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return err
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to check %s: %v", path, err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("integrity check of %s failed: %s", path, strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -2,168 +2,370 @@
 package database
 
 import (
-	"database/sql"
+	"blog-portfolio/migrations"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
 )
 
+// ErrChecksumMismatch is returned when a migration file has changed since it was
+// applied. Applied migrations must never be edited; add a new migration instead.
+var ErrChecksumMismatch = errors.New("applied migration has been modified")
+
+// Migration is a schema change and the SQL that reverses it
 type Migration struct {
-	ID      int
-	Name    string
-	Content string
+	Version  int
+	Name     string
+	Up       string
+	Down     string // Empty when the migration can't be rolled back
+	Checksum string // SHA-256 of Up
 }
 
-func (db *Database) RunMigrations() error {
-	// Create migrations table if it doesn't exist
-	_, err := db.Exec(`
-        CREATE TABLE IF NOT EXISTS schema_migrations (
-            version INTEGER PRIMARY KEY,
-            name TEXT NOT NULL,
-            applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-        )
-    `)
+// MigrationStatus describes a migration and whether it has been applied
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time // Nil while the migration is pending
+	Modified  bool       // The file has changed since it was applied
+	Missing   bool       // Applied, but its file no longer exists
+}
+
+// migrationFilePattern matches migration files such as 000001_create_posts_table.up.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// LoadMigrations reads the migrations in fsys, ordered by version
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
 	if err != nil {
-		return fmt.Errorf("failed to create migrations table: %v", err)
+		return nil, err
 	}
 
-	// Get all migration files
-	migrations, err := loadMigrationFiles()
-	if err != nil {
-		return fmt.Errorf("failed to load migration files: %v", err)
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !migrationFilePattern.MatchString(entry.Name()) {
+			continue
+		}
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version: %s", entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+			sum := sha256.Sum256(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(content)
+		}
 	}
 
-	// Begin transaction
-	tx, err := db.Begin()
+	list := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Checksum == "" {
+			return nil, fmt.Errorf("migration %06d_%s has no up file", migration.Version, migration.Name)
+		}
+		list = append(list, *migration)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+
+	return list, nil
+}
+
+// appliedMigration is a row of the schema_migrations table
+type appliedMigration struct {
+	version   int
+	name      string
+	checksum  string // Empty for migrations recorded before checksums were kept
+	appliedAt time.Time
+}
+
+// RunMigrations applies every pending migration
+func (db *Database) RunMigrations() error {
+	_, err := db.MigrateUp(false)
+	return err
+}
+
+// MigrateUp applies pending migrations in order, each in its own transaction, and
+// returns them. A dry run applies them all in one transaction that is rolled back,
+// so broken SQL is reported without changing the database.
+func (db *Database) MigrateUp(dryRun bool) ([]Migration, error) {
+	all, applied, err := db.loadMigrationState()
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %v", err)
+		return nil, err
+	}
+	if err := verifyChecksums(all, applied); err != nil {
+		return nil, err
 	}
-	defer tx.Rollback()
 
-	// Run each migration
-	for _, migration := range migrations {
-		applied, err := isMigrationApplied(tx, migration.ID)
-		if err != nil {
-			return fmt.Errorf("failed to check migration status: %v", err)
+	var pending []Migration
+	for _, migration := range all {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
 		}
+	}
 
-		if !applied {
-			db.logger.Info("Applying migration:", migration.Name)
-
-			// Execute migration
-			if _, err := tx.Exec(migration.Content); err != nil {
-				return fmt.Errorf("failed to apply migration %s: %v", migration.Name, err)
-			}
+	if dryRun {
+		return pending, db.dryRun(pending, func(tx *Tx, m Migration) error {
+			_, err := tx.Exec(m.Up)
+			return err
+		})
+	}
 
-			// Record migration
-			if err := recordMigration(tx, migration); err != nil {
-				return fmt.Errorf("failed to record migration %s: %v", migration.Name, err)
+	// Migrations recorded before checksums were kept are trusted as they are now
+	for _, migration := range all {
+		if a, ok := applied[migration.Version]; ok && a.checksum == "" {
+			if _, err := db.Exec("UPDATE schema_migrations SET checksum = ? WHERE version = ?", migration.Checksum, migration.Version); err != nil {
+				return nil, fmt.Errorf("failed to record checksum of migration %06d: %v", migration.Version, err)
 			}
 		}
 	}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit migrations: %v", err)
+	for i, migration := range pending {
+		db.logger.Info(fmt.Sprintf("Applying migration %06d_%s", migration.Version, migration.Name))
+		err := db.inTransaction(func(tx *Tx) error {
+			if _, err := tx.Exec(migration.Up); err != nil {
+				return err
+			}
+			_, err := tx.Exec(
+				"INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)",
+				migration.Version, migration.Name, migration.Checksum,
+			)
+			return err
+		})
+		if err != nil {
+			return pending[:i], fmt.Errorf("failed to apply migration %06d_%s: %v", migration.Version, migration.Name, err)
+		}
 	}
 
-	return nil
+	return pending, nil
 }
 
-func loadMigrationFiles() ([]Migration, error) {
-	migrationsDir := "./migrations"
-	entries, err := os.ReadDir(migrationsDir)
+// MigrateDown rolls back the last steps applied migrations, newest first, each in its
+// own transaction, and returns them. A dry run rolls them back in one transaction
+// that is then itself rolled back.
+func (db *Database) MigrateDown(steps int, dryRun bool) ([]Migration, error) {
+	all, applied, err := db.loadMigrationState()
 	if err != nil {
 		return nil, err
 	}
+	if err := verifyChecksums(all, applied); err != nil {
+		return nil, err
+	}
 
-	var migrations []Migration
-	for _, entry := range entries {
-		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".up.sql" {
-			content, err := os.ReadFile(filepath.Join(migrationsDir, entry.Name()))
-			if err != nil {
-				return nil, err
-			}
+	files := make(map[int]Migration, len(all))
+	for _, migration := range all {
+		files[migration.Version] = migration
+	}
+
+	versions := make([]int, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no migrations to roll back")
+	}
+	if steps > len(versions) {
+		steps = len(versions)
+	}
+
+	rollback := make([]Migration, 0, steps)
+	for _, version := range versions[:steps] {
+		migration, ok := files[version]
+		if !ok {
+			return nil, fmt.Errorf("migration %06d_%s was applied but its file no longer exists", version, applied[version].name)
+		}
+		if migration.Down == "" {
+			return nil, fmt.Errorf("migration %06d_%s has no down migration", version, migration.Name)
+		}
+		rollback = append(rollback, migration)
+	}
 
-			// Parse migration ID from filename
-			var id int
-			_, err = fmt.Sscanf(entry.Name(), "%d_", &id)
-			if err != nil {
-				return nil, fmt.Errorf("invalid migration filename format: %s", entry.Name())
+	if dryRun {
+		return rollback, db.dryRun(rollback, func(tx *Tx, m Migration) error {
+			_, err := tx.Exec(m.Down)
+			return err
+		})
+	}
+
+	for i, migration := range rollback {
+		db.logger.Info(fmt.Sprintf("Rolling back migration %06d_%s", migration.Version, migration.Name))
+		err := db.inTransaction(func(tx *Tx) error {
+			if _, err := tx.Exec(migration.Down); err != nil {
+				return err
 			}
+			_, err := tx.Exec("DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+			return err
+		})
+		if err != nil {
+			return rollback[:i], fmt.Errorf("failed to roll back migration %06d_%s: %v", migration.Version, migration.Name, err)
+		}
+	}
 
-			migrations = append(migrations, Migration{
-				ID:      id,
-				Name:    entry.Name(),
-				Content: string(content),
-			})
+	return rollback, nil
+}
+
+// MigrationStatus lists every migration, applied or pending, in order. Applied
+// migrations whose files have since been removed are included too.
+func (db *Database) MigrationStatus() ([]MigrationStatus, error) {
+	all, applied, err := db.loadMigrationState()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(all))
+	for _, migration := range all {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if a, ok := applied[migration.Version]; ok {
+			appliedAt := a.appliedAt
+			status.AppliedAt = &appliedAt
+			status.Modified = a.checksum != "" && a.checksum != migration.Checksum
+			delete(applied, migration.Version)
 		}
+		statuses = append(statuses, status)
+	}
+	for _, a := range applied {
+		appliedAt := a.appliedAt
+		statuses = append(statuses, MigrationStatus{
+			Version:   a.version,
+			Name:      a.name,
+			AppliedAt: &appliedAt,
+			Missing:   true,
+		})
 	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
 
-	return migrations, nil
+	return statuses, nil
 }
 
-func isMigrationApplied(tx *sql.Tx, version int) (bool, error) {
-	var exists bool
-	err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = ?)", version).Scan(&exists)
+// loadMigrationState reads the embedded migrations and the ones recorded as applied
+func (db *Database) loadMigrationState() ([]Migration, map[int]appliedMigration, error) {
+	if err := db.createMigrationsTable(); err != nil {
+		return nil, nil, err
+	}
+
+	files, err := migrations.For(db.driver)
+	if err != nil {
+		return nil, nil, err
+	}
+	list, err := LoadMigrations(files)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load migration files: %v", err)
+	}
+
+	rows, err := db.Query("SELECT version, name, checksum, applied_at FROM schema_migrations")
 	if err != nil {
-		return false, err
+		return nil, nil, fmt.Errorf("failed to read applied migrations: %v", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]appliedMigration)
+	for rows.Next() {
+		var a appliedMigration
+		if err := rows.Scan(&a.version, &a.name, &a.checksum, &a.appliedAt); err != nil {
+			return nil, nil, err
+		}
+		applied[a.version] = a
 	}
-	return exists, nil
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return list, applied, nil
 }
 
-func recordMigration(tx *sql.Tx, migration Migration) error {
-	_, err := tx.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)",
-		migration.ID, migration.Name)
-	return err
+// verifyChecksums fails if any applied migration's file has changed since
+func verifyChecksums(migrations []Migration, applied map[int]appliedMigration) error {
+	for _, migration := range migrations {
+		a, ok := applied[migration.Version]
+		if ok && a.checksum != "" && a.checksum != migration.Checksum {
+			return fmt.Errorf("%w: %06d_%s", ErrChecksumMismatch, migration.Version, migration.Name)
+		}
+	}
+	return nil
 }
 
-func (db *Database) RollbackMigration() error {
-	// Get the last applied migration
-	var version int
-	var name string
-	err := db.QueryRow(`
-        SELECT version, name 
-        FROM schema_migrations 
-        ORDER BY version DESC 
-        LIMIT 1
-    `).Scan(&version, &name)
+func (db *Database) createMigrationsTable() error {
+	timestamp := "TIMESTAMP"
+	if db.driver == Postgres {
+		timestamp = "TIMESTAMPTZ"
+	}
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            version INTEGER PRIMARY KEY,
+            name TEXT NOT NULL,
+            checksum TEXT NOT NULL DEFAULT '',
+            applied_at ` + timestamp + ` DEFAULT CURRENT_TIMESTAMP
+        )
+    `)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return fmt.Errorf("no migrations to rollback")
-		}
-		return fmt.Errorf("failed to get last migration: %v", err)
+		return fmt.Errorf("failed to create migrations table: %v", err)
 	}
 
-	// Read the down migration file
-	downFile := fmt.Sprintf("./migrations/%d_%s.down.sql", version, name)
-	content, err := os.ReadFile(downFile)
+	// SQLite tables created before checksums were kept lack the column
+	if db.driver != SQLite {
+		return nil
+	}
+	var hasChecksum bool
+	err = db.QueryRow("SELECT COUNT(*) > 0 FROM pragma_table_info('schema_migrations') WHERE name = 'checksum'").Scan(&hasChecksum)
 	if err != nil {
-		return fmt.Errorf("failed to read down migration: %v", err)
+		return fmt.Errorf("failed to inspect migrations table: %v", err)
+	}
+	if !hasChecksum {
+		if _, err := db.Exec("ALTER TABLE schema_migrations ADD COLUMN checksum TEXT NOT NULL DEFAULT ''"); err != nil {
+			return fmt.Errorf("failed to upgrade migrations table: %v", err)
+		}
 	}
 
-	// Begin transaction
+	return nil
+}
+
+// inTransaction runs fn in a transaction, committing it if fn succeeds
+func (db *Database) inTransaction(fn func(tx *Tx) error) error {
 	tx, err := db.Begin()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %v", err)
 	}
 	defer tx.Rollback()
 
-	// Execute down migration
-	if _, err := tx.Exec(string(content)); err != nil {
-		return fmt.Errorf("failed to execute down migration: %v", err)
+	if err := fn(tx); err != nil {
+		return err
 	}
+	return tx.Commit()
+}
 
-	// Remove migration record
-	if _, err := tx.Exec("DELETE FROM schema_migrations WHERE version = ?", version); err != nil {
-		return fmt.Errorf("failed to remove migration record: %v", err)
+// dryRun runs each migration with fn in a single transaction that is always rolled back
+func (db *Database) dryRun(list []Migration, fn func(tx *Tx, m Migration) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
 	}
+	defer tx.Rollback()
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit rollback: %v", err)
+	for _, migration := range list {
+		if err := fn(tx, migration); err != nil {
+			return fmt.Errorf("migration %06d_%s would fail: %v", migration.Version, migration.Name, err)
+		}
 	}
-
 	return nil
 }
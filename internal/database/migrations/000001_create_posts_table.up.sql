@@ -1,41 +0,0 @@
--- migrations/000001_create_posts_table.up.sql
-CREATE TABLE IF NOT EXISTS posts (
-    id INTEGER PRIMARY KEY AUTOINCREMENT,
-    title TEXT NOT NULL,
-    slug TEXT NOT NULL UNIQUE,
-    content TEXT NOT NULL,
-    description TEXT,
-    cover_image TEXT,
-    published BOOLEAN DEFAULT FALSE,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    published_at TIMESTAMP
-);
-
-CREATE INDEX idx_posts_slug ON posts(slug);
-CREATE INDEX idx_posts_published ON posts(published);
-
--- migrations/000001_create_posts_table.down.sql
-DROP TABLE IF EXISTS posts;
-
--- migrations/000002_create_tags_table.up.sql
-CREATE TABLE IF NOT EXISTS tags (
-    id INTEGER PRIMARY KEY AUTOINCREMENT,
-    name TEXT NOT NULL UNIQUE,
-    slug TEXT NOT NULL UNIQUE,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-);
-
-CREATE TABLE IF NOT EXISTS post_tags (
-    post_id INTEGER NOT NULL,
-    tag_id INTEGER NOT NULL,
-    PRIMARY KEY (post_id, tag_id),
-    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
-    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
-);
-
-CREATE INDEX idx_tags_slug ON tags(slug);
-
--- migrations/000002_create_tags_table.down.sql
-DROP TABLE IF EXISTS post_tags;
-DROP TABLE IF EXISTS tags;
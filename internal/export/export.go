@@ -0,0 +1,425 @@
+// internal/export/export.go
+package export
+
+import (
+	"blog-portfolio/internal/handlers"
+	"blog-portfolio/internal/logger"
+	"blog-portfolio/internal/models"
+	"blog-portfolio/internal/service"
+	"blog-portfolio/internal/storage"
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// manifestName is the file in OutDir listing every file the last export produced, so
+// the next one can remove those it no longer produces without touching anything else
+const manifestName = ".export-manifest"
+
+// Options configures an export
+type Options struct {
+	OutDir    string // Directory the site is written to
+	StaticDir string // Static assets copied to /static
+	BaseURL   string // Public URL the exported site is hosted at
+	OGImages  bool   // Export the generated share images of posts without a cover image
+}
+
+// Result counts the files an export wrote and those it left alone because their
+// content hadn't changed
+type Result struct {
+	Written   int
+	Unchanged int
+	Media     int // Uploaded files copied for the first time
+	Removed   int // Files from the previous export that are no longer part of the site
+}
+
+// Exporter renders the public site to static files. Pages are rendered by making
+// requests to the app's own handler, so the output is exactly what the server would
+// send. Listing URLs that rely on query strings, which static hosts can't route, are
+// written as directories instead and links to them are rewritten:
+//
+//	/blog?page=2           -> /blog/page/2/
+//	/blog?tag=go           -> /tags/go/
+//	/blog?tag=go&page=2    -> /tags/go/page/2/
+//
+// Search needs the server and is not exported.
+type Exporter struct {
+	logger  *logger.Logger
+	handler http.Handler
+	posts   *service.PostService
+	tags    *service.TagService
+	media   *service.MediaService
+	opts    Options
+
+	baseURL string // Base URL without a trailing slash
+	prefix  string // Path of the base URL, prepended to root-relative links
+	result  Result
+	files   map[string]bool // Slash-separated paths in OutDir produced by this run
+}
+
+func New(logger *logger.Logger, handler http.Handler, posts *service.PostService, tags *service.TagService, media *service.MediaService, opts Options) (*Exporter, error) {
+	base, err := url.Parse(opts.BaseURL)
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q", opts.BaseURL)
+	}
+
+	return &Exporter{
+		logger:  logger,
+		handler: handler,
+		posts:   posts,
+		tags:    tags,
+		media:   media,
+		opts:    opts,
+		baseURL: strings.TrimSuffix(opts.BaseURL, "/"),
+		prefix:  strings.TrimSuffix(base.Path, "/"),
+	}, nil
+}
+
+// Run exports the site. Files whose content is unchanged are not rewritten, so
+// deploy tools that sync by modification time only upload what changed. Files an
+// earlier export wrote that the site no longer has, like the pages of unpublished
+// posts, are removed.
+func (e *Exporter) Run(ctx context.Context) (Result, error) {
+	e.result = Result{}
+	e.files = map[string]bool{}
+
+	published := true
+	posts, err := e.posts.ListPosts(ctx, models.PostFilter{Published: &published})
+	if err != nil {
+		return e.result, err
+	}
+	tags, err := e.tags.ListPublishedTags(ctx)
+	if err != nil {
+		return e.result, err
+	}
+
+	pages := []string{"/"}
+	pages = append(pages, listingPages("", len(posts))...)
+	authors := map[string]bool{}
+	for _, post := range posts {
+		pages = append(pages, "/blog/"+url.PathEscape(post.Slug))
+		if e.opts.OGImages && post.CoverImage == "" {
+			pages = append(pages, "/og/"+url.PathEscape(post.Slug)+".png")
+		}
+		// Everyone with a published post has an author page
+		if post.Author != nil && !authors[post.Author.Username] {
+			authors[post.Author.Username] = true
+			pages = append(pages, "/authors/"+url.PathEscape(post.Author.Username))
+		}
+	}
+	for _, tag := range tags {
+		tagged, err := e.posts.ListPosts(ctx, models.PostFilter{Published: &published, Tag: tag.Slug})
+		if err != nil {
+			return e.result, err
+		}
+		pages = append(pages, listingPages(tag.Slug, len(tagged))...)
+
+		feeds := "/tags/" + url.PathEscape(tag.Slug)
+		pages = append(pages, feeds+"/feed.xml", feeds+"/atom.xml", feeds+"/feed.json")
+	}
+	pages = append(pages, "/feed.xml", "/atom.xml", "/feed.json", "/robots.txt", "/sitemap.xml", "/static/css/highlight.css")
+
+	// Static assets go first so generated files, like highlight.css, win
+	if err := e.copyStatic(); err != nil {
+		return e.result, err
+	}
+
+	for _, page := range pages {
+		if err := e.exportPage(ctx, page); err != nil {
+			return e.result, err
+		}
+	}
+
+	// A large sitemap is split into /sitemap-1.xml, /sitemap-2.xml and so on
+	for i := 1; ; i++ {
+		page := fmt.Sprintf("/sitemap-%d.xml", i)
+		body, status, err := e.fetch(ctx, page)
+		if err != nil {
+			return e.result, err
+		}
+		if status == http.StatusNotFound {
+			break
+		}
+		if err := e.write(page, e.rewriteText(body)); err != nil {
+			return e.result, err
+		}
+	}
+
+	// Uploaded files never change once stored, so existing copies are kept
+	copied, _, err := e.media.CopyFiles(ctx, storage.NewLocal(filepath.Join(e.opts.OutDir, "media")))
+	e.result.Media = copied
+	if err != nil {
+		return e.result, fmt.Errorf("copying media: %w", err)
+	}
+	names, err := e.media.ListFiles(ctx)
+	if err != nil {
+		return e.result, err
+	}
+	for _, name := range names {
+		e.files["media/"+name] = true
+	}
+
+	if err := e.removeStale(); err != nil {
+		return e.result, fmt.Errorf("removing old files: %w", err)
+	}
+	return e.result, nil
+}
+
+// removeStale deletes the files listed in the previous export's manifest that this
+// run didn't produce, and records this run's files for the next one
+func (e *Exporter) removeStale() error {
+	manifest := filepath.Join(e.opts.OutDir, manifestName)
+	previous, err := os.ReadFile(manifest)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, name := range strings.Split(string(previous), "\n") {
+		// Only ever remove plain relative paths inside OutDir
+		if name == "" || e.files[name] || !fs.ValidPath(name) {
+			continue
+		}
+		target := filepath.Join(e.opts.OutDir, filepath.FromSlash(name))
+		if err := os.Remove(target); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		e.result.Removed++
+		e.logger.Info("Removed", name)
+
+		// Drop directories the removal left empty, like those of a post's pages
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			if os.Remove(filepath.Join(e.opts.OutDir, filepath.FromSlash(dir))) != nil {
+				break
+			}
+		}
+	}
+
+	names := make([]string, 0, len(e.files))
+	for name := range e.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return os.WriteFile(manifest, []byte(strings.Join(names, "\n")+"\n"), 0o644)
+}
+
+// listingPages returns the URLs of every page of the blog listing, or of a tag's
+// listing when tag isn't empty
+func listingPages(tag string, posts int) []string {
+	count := (posts + handlers.BlogPageSize - 1) / handlers.BlogPageSize
+	if count < 1 {
+		count = 1
+	}
+
+	pages := make([]string, 0, count)
+	for page := 1; page <= count; page++ {
+		query := url.Values{}
+		if tag != "" {
+			query.Set("tag", tag)
+		}
+		if page > 1 {
+			query.Set("page", strconv.Itoa(page))
+		}
+		if len(query) == 0 {
+			pages = append(pages, "/blog")
+		} else {
+			pages = append(pages, "/blog?"+query.Encode())
+		}
+	}
+	return pages
+}
+
+// exportPage renders one URL and writes it to the file a static host serves it from
+func (e *Exporter) exportPage(ctx context.Context, page string) error {
+	body, status, err := e.fetch(ctx, page)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", page, status)
+	}
+
+	target := staticPath(page)
+	switch path.Ext(target) {
+	case ".html":
+		body = e.rewriteHTML(page, body)
+	case ".xml", ".json", ".txt":
+		body = e.rewriteText(body)
+	}
+
+	return e.write(target, body)
+}
+
+// fetch renders a URL through the app's handler
+func (e *Exporter) fetch(ctx context.Context, page string) ([]byte, int, error) {
+	req := httptest.NewRequest(http.MethodGet, page, nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	e.handler.ServeHTTP(rec, req)
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	return rec.Body.Bytes(), rec.Code, nil
+}
+
+// staticPath returns the file a URL is written to
+func staticPath(page string) string {
+	u, _ := url.Parse(page)
+	p := exportURL(u).Path
+	if strings.HasSuffix(p, "/") {
+		return p + "index.html"
+	}
+	if path.Ext(p) == "" {
+		return p + "/index.html"
+	}
+	return p
+}
+
+// exportURL maps a listing URL with a query string to its directory in the export
+func exportURL(u *url.URL) *url.URL {
+	if u.Path != "/blog" || u.RawQuery == "" {
+		return u
+	}
+
+	query := u.Query()
+	p := "/blog/"
+	if tag := query.Get("tag"); tag != "" {
+		p = "/tags/" + url.PathEscape(tag) + "/"
+	}
+	if page, _ := strconv.Atoi(query.Get("page")); page > 1 {
+		p += "page/" + strconv.Itoa(page) + "/"
+	}
+
+	return &url.URL{Path: p, Fragment: u.Fragment}
+}
+
+var (
+	// attrPattern matches the attributes links are rewritten in
+	attrPattern = regexp.MustCompile(`\s(href|src|content|action|hx-get)="([^"]*)"`)
+
+	// tagLinkPattern matches tag listing URLs in feeds and sitemaps
+	tagLinkPattern = regexp.MustCompile(`/blog\?tag=([A-Za-z0-9_.~%-]+)`)
+)
+
+// rewriteHTML points the links in a rendered page at their exported locations
+func (e *Exporter) rewriteHTML(page string, body []byte) []byte {
+	current, _ := url.Parse(page)
+
+	return attrPattern.ReplaceAllFunc(body, func(match []byte) []byte {
+		parts := attrPattern.FindSubmatch(match)
+		attr, value := string(parts[1]), html.UnescapeString(string(parts[2]))
+
+		// HTMX requests with a query string can't be answered by a static host, so
+		// drop them and let the plain link do the navigation
+		if attr == "hx-get" && strings.Contains(value, "?") {
+			return nil
+		}
+
+		link, ok := e.link(current, value)
+		if !ok {
+			return match
+		}
+		return []byte(fmt.Sprintf(` %s="%s"`, attr, html.EscapeString(link)))
+	})
+}
+
+// link rewrites a link found on the page at current. Links outside the site are
+// left alone.
+func (e *Exporter) link(current *url.URL, value string) (string, bool) {
+	absolute := false
+	switch {
+	case strings.HasPrefix(value, e.baseURL+"/") || value == e.baseURL:
+		absolute = true
+		value = strings.TrimPrefix(value, e.baseURL)
+		if value == "" {
+			value = "/"
+		}
+	case strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "//"):
+	case strings.HasPrefix(value, "?"):
+		// Relative query links, like pagination, keep the page's other parameters
+		query := current.Query()
+		extra, err := url.ParseQuery(value[1:])
+		if err != nil {
+			return "", false
+		}
+		for key := range extra {
+			query.Set(key, extra.Get(key))
+		}
+		value = current.Path + "?" + query.Encode()
+	default:
+		return "", false
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return "", false
+	}
+	mapped := exportURL(u).String()
+
+	if absolute {
+		return e.baseURL + mapped, true
+	}
+	return e.prefix + mapped, true
+}
+
+// rewriteText points tag listing URLs in feeds and sitemaps at their exported locations
+func (e *Exporter) rewriteText(body []byte) []byte {
+	return tagLinkPattern.ReplaceAll(body, []byte("/tags/$1/"))
+}
+
+// copyStatic copies the static assets into the export
+func (e *Exporter) copyStatic() error {
+	if _, err := os.Stat(e.opts.StaticDir); os.IsNotExist(err) {
+		e.logger.Info("No static assets to copy, " + e.opts.StaticDir + " does not exist")
+		return nil
+	}
+
+	return filepath.WalkDir(e.opts.StaticDir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(e.opts.StaticDir, name)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		return e.write("/static/"+filepath.ToSlash(rel), data)
+	})
+}
+
+// write stores a file in the export unless it already has the same content
+func (e *Exporter) write(name string, data []byte) error {
+	name = strings.TrimPrefix(name, "/")
+	target := filepath.Join(e.opts.OutDir, filepath.FromSlash(name))
+	e.files[name] = true
+
+	if existing, err := os.ReadFile(target); err == nil && bytes.Equal(existing, data) {
+		e.result.Unchanged++
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(target, data, 0o644); err != nil {
+		return err
+	}
+	e.result.Written++
+	return nil
+}
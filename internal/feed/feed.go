@@ -0,0 +1,46 @@
+// internal/feed/feed.go
+package feed
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Feed is a syndication feed, encoded as RSS 2.0, Atom or JSON Feed 1.1. All URLs
+// must be absolute.
+type Feed struct {
+	Title       string
+	Description string
+	HomeURL     string // The page the feed mirrors
+	FeedURL     string // Where this feed is served
+	Author      string
+	Language    string
+	Updated     time.Time
+	Items       []Item
+}
+
+// Item is a single post in a feed
+type Item struct {
+	ID          string // Stable, unique ID; the post's permalink
+	Title       string
+	URL         string
+	Summary     string // Plain text
+	ContentHTML string // Full content, empty when the feed only carries summaries
+	Image       string
+	Author      string // Empty to credit the feed's author
+	Tags        []string
+	Published   time.Time
+	Updated     time.Time
+}
+
+// urlAttrPattern matches src and href attributes holding root-relative URLs
+var urlAttrPattern = regexp.MustCompile(`(\s(?:src|href|poster))="/([^/"][^"]*)?"`)
+
+// AbsoluteURLs rewrites root-relative links and image sources in content to absolute
+// URLs under baseURL, since feed readers show content away from the site
+func AbsoluteURLs(content, baseURL string) string {
+	// Escape $ so the base URL is used literally in the replacement template
+	base := strings.ReplaceAll(baseURL, "$", "$$")
+	return urlAttrPattern.ReplaceAllString(content, `$1="`+base+`/$2"`)
+}
@@ -0,0 +1,80 @@
+// internal/feed/json.go
+package feed
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+type jsonFeed struct {
+	Version     string       `json:"version"`
+	Title       string       `json:"title"`
+	HomePageURL string       `json:"home_page_url"`
+	FeedURL     string       `json:"feed_url"`
+	Description string       `json:"description,omitempty"`
+	Language    string       `json:"language,omitempty"`
+	Authors     []jsonAuthor `json:"authors,omitempty"`
+	Items       []jsonItem   `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonItem struct {
+	ID            string       `json:"id"`
+	URL           string       `json:"url"`
+	Title         string       `json:"title"`
+	ContentHTML   string       `json:"content_html,omitempty"`
+	ContentText   string       `json:"content_text,omitempty"`
+	Summary       string       `json:"summary,omitempty"`
+	Image         string       `json:"image,omitempty"`
+	DatePublished string       `json:"date_published"`
+	DateModified  string       `json:"date_modified"`
+	Authors       []jsonAuthor `json:"authors,omitempty"`
+	Tags          []string     `json:"tags,omitempty"`
+}
+
+// WriteJSON encodes the feed as JSON Feed 1.1
+func (f *Feed) WriteJSON(w io.Writer) error {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.HomeURL,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Language:    f.Language,
+		Items:       []jsonItem{},
+	}
+	if f.Author != "" {
+		doc.Authors = []jsonAuthor{{Name: f.Author}}
+	}
+
+	for _, item := range f.Items {
+		ji := jsonItem{
+			ID:            item.ID,
+			URL:           item.URL,
+			Title:         item.Title,
+			ContentHTML:   item.ContentHTML,
+			Summary:       item.Summary,
+			Image:         item.Image,
+			DatePublished: item.Published.UTC().Format(time.RFC3339),
+			DateModified:  item.Updated.UTC().Format(time.RFC3339),
+			Tags:          item.Tags,
+		}
+		if item.Author != "" {
+			ji.Authors = []jsonAuthor{{Name: item.Author}}
+		}
+		// Every item needs content, so summary-only feeds repeat the summary as text
+		if ji.ContentHTML == "" {
+			ji.ContentText = item.Summary
+		}
+		doc.Items = append(doc.Items, ji)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,105 @@
+// internal/feed/rss.go
+package feed
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+type rssDoc struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	DCNS      string     `xml:"xmlns:dc,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Generator     string    `xml:"generator"`
+	SelfLink      rssLink   `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string      `xml:"title"`
+	Link        string      `xml:"link"`
+	GUID        rssGUID     `xml:"guid"`
+	PubDate     string      `xml:"pubDate"`
+	Creator     string      `xml:"dc:creator,omitempty"` // RSS's own author element needs an email address
+	Description string      `xml:"description,omitempty"`
+	Content     *rssContent `xml:"content:encoded,omitempty"`
+	Categories  []string    `xml:"category,omitempty"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type rssContent struct {
+	Value string `xml:",cdata"`
+}
+
+// WriteRSS encodes the feed as RSS 2.0
+func (f *Feed) WriteRSS(w io.Writer) error {
+	doc := rssDoc{
+		Version:   "2.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		DCNS:      "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.HomeURL,
+			Description: f.Description,
+			Language:    f.Language,
+			Generator:   "blog-portfolio",
+			SelfLink:    rssLink{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	if !f.Updated.IsZero() {
+		doc.Channel.LastBuildDate = f.Updated.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, item := range f.Items {
+		ri := rssItem{
+			Title:       item.Title,
+			Link:        item.URL,
+			GUID:        rssGUID{Value: item.ID, IsPermaLink: item.ID == item.URL},
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+			Creator:     item.Author,
+			Description: item.Summary,
+			Categories:  item.Tags,
+		}
+		if item.ContentHTML != "" {
+			ri.Content = &rssContent{Value: item.ContentHTML}
+		}
+		doc.Channel.Items = append(doc.Channel.Items, ri)
+	}
+
+	return writeXML(w, doc)
+}
+
+func writeXML(w io.Writer, doc interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
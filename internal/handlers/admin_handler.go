@@ -2,11 +2,17 @@
 package handlers
 
 import (
+	"blog-portfolio/internal/backup"
+	"blog-portfolio/internal/config"
 	"blog-portfolio/internal/logger"
+	"blog-portfolio/internal/middleware"
 	"blog-portfolio/internal/models"
 	"blog-portfolio/internal/service"
 	"blog-portfolio/internal/utils"
 	"blog-portfolio/web/pages/admin"
+	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -15,16 +21,24 @@ import (
 )
 
 type AdminHandlers struct {
-	logger *logger.Logger
-	posts  *service.PostService
-	tags   *service.TagService
+	logger   *logger.Logger
+	config   *config.Config
+	posts    *service.PostService
+	tags     *service.TagService
+	previews *service.PreviewService
+	media    *service.MediaService
+	backups  *backup.Manager
 }
 
-func NewAdminHandlers(logger *logger.Logger, postService *service.PostService, tagService *service.TagService) *AdminHandlers {
+func NewAdminHandlers(logger *logger.Logger, cfg *config.Config, postService *service.PostService, tagService *service.TagService, previewService *service.PreviewService, mediaService *service.MediaService, backups *backup.Manager) *AdminHandlers {
 	return &AdminHandlers{
-		logger: logger,
-		posts:  postService,
-		tags:   tagService,
+		logger:   logger,
+		config:   cfg,
+		posts:    postService,
+		tags:     tagService,
+		previews: previewService,
+		media:    mediaService,
+		backups:  backups,
 	}
 }
 
@@ -35,7 +49,8 @@ func (h *AdminHandlers) ShowDashboard() http.HandlerFunc {
 
 		// Get post statistics
 		filter := models.PostFilter{
-			Limit: 5, // Only get recent 5 posts
+			Limit:    5, // Only get recent 5 posts
+			AuthorID: ownPostsOnly(r),
 		}
 		recentPosts, err := h.posts.ListPosts(ctx, filter)
 		if err != nil {
@@ -88,8 +103,9 @@ func (h *AdminHandlers) ShowPosts() http.HandlerFunc {
 
 		// Get posts
 		filter := models.PostFilter{
-			Limit:  limit,
-			Offset: offset,
+			Limit:    limit,
+			Offset:   offset,
+			AuthorID: ownPostsOnly(r),
 		}
 		posts, err := h.posts.ListPosts(ctx, filter)
 		if err != nil {
@@ -127,8 +143,9 @@ func (h *AdminHandlers) ShowCreatePost() http.HandlerFunc {
 		}
 
 		data := admin.PostEditorData{
-			IsNew: true,
-			Tags:  tags,
+			IsNew:      true,
+			Tags:       tags,
+			CanPublish: canPublish(r),
 		}
 
 		err = admin.PostEditor(data).Render(r.Context(), w)
@@ -142,38 +159,34 @@ func (h *AdminHandlers) ShowCreatePost() http.HandlerFunc {
 // ShowEditPost handles displaying the edit post form
 func (h *AdminHandlers) ShowEditPost() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Get post ID from URL
-		idStr := chi.URLParam(r, "id")
-		id, err := strconv.ParseInt(idStr, 10, 64)
-		if err != nil {
-			http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		// Get post
+		post, ok := h.editablePost(w, r)
+		if !ok {
 			return
 		}
 
-		// Get post
-		post, err := h.posts.GetPostByID(r.Context(), id)
+		// Get all available tags
+		tags, err := h.posts.ListTags(r.Context())
 		if err != nil {
-			h.logger.Error("Error fetching post:", err)
+			h.logger.Error("Error fetching tags:", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			return
 		}
-		if post == nil {
-			http.NotFound(w, r)
-			return
-		}
 
-		// Get all available tags
-		tags, err := h.posts.ListTags(r.Context())
+		// Get active preview links
+		previewLinks, err := h.previews.ListLinks(r.Context(), post)
 		if err != nil {
-			h.logger.Error("Error fetching tags:", err)
+			h.logger.Error("Error fetching preview links:", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			return
 		}
 
 		data := admin.PostEditorData{
-			Post:  post,
-			Tags:  tags,
-			IsNew: false,
+			Post:         post,
+			Tags:         tags,
+			IsNew:        false,
+			PreviewLinks: previewLinks,
+			CanPublish:   canPublish(r),
 		}
 
 		err = admin.PostEditor(data).Render(r.Context(), w)
@@ -194,12 +207,14 @@ func (h *AdminHandlers) HandleCreatePost() http.HandlerFunc {
 			return
 		}
 
-		// Get action (draft or publish)
+		// Get action (draft, schedule or publish)
 		action := r.FormValue("action")
 		published := action == "publish" // This is correct but let's add logging
 
 		h.logger.Info("Post action:", action, "Published:", published) // Add logging
 
+		scheduledAt, scheduleErr := parseScheduledAt(r, action)
+
 		// Get selected tag IDs
 		var tagIDs []int64
 		for _, idStr := range r.Form["tags[]"] {
@@ -213,10 +228,13 @@ func (h *AdminHandlers) HandleCreatePost() http.HandlerFunc {
 		// Create post with proper publishing status
 		post := &models.Post{
 			Title:       r.FormValue("title"),
+			Slug:        r.FormValue("slug"),
 			Content:     r.FormValue("content"),
 			Description: r.FormValue("description"),
 			CoverImage:  r.FormValue("cover_image"),
 			Published:   published,
+			HideTOC:     r.FormValue("hide_toc") == "on",
+			ScheduledAt: scheduledAt,
 		}
 
 		// Set published date if being published
@@ -226,16 +244,24 @@ func (h *AdminHandlers) HandleCreatePost() http.HandlerFunc {
 		}
 
 		// Save post
-		err := h.posts.CreatePost(r.Context(), post, tagIDs)
+		err := scheduleErr
+		if err == nil && action != "draft" && !canPublish(r) {
+			err = errCannotPublish
+		}
+		if err == nil {
+			creditAuthor(r, post)
+			err = h.posts.CreatePost(r.Context(), post, tagIDs, revisionInfo(r))
+		}
 		if err != nil {
 			h.logger.Error("Error creating post:", err)
 			// Re-render form with error
 			tags, _ := h.posts.ListTags(r.Context())
 			data := admin.PostEditorData{
-				Post:  post,
-				Tags:  tags,
-				IsNew: true,
-				Error: "Failed to create post: " + err.Error(),
+				Post:       post,
+				Tags:       tags,
+				IsNew:      true,
+				Error:      postFormError("create", err),
+				CanPublish: canPublish(r),
 			}
 			admin.PostEditor(data).Render(r.Context(), w)
 			return
@@ -246,17 +272,51 @@ func (h *AdminHandlers) HandleCreatePost() http.HandlerFunc {
 	}
 }
 
+// errCannotPublish is returned when a contributor tries to publish or schedule a post
+var errCannotPublish = errors.New("contributors can only save drafts; an editor will publish the post")
+
+// postFormError describes a failed save for the editor
+func postFormError(action string, err error) string {
+	if errors.Is(err, service.ErrSlugTaken) {
+		return "That URL slug is already used by another post. Choose a different one."
+	}
+	if errors.Is(err, errCannotPublish) {
+		return "Your role can't publish or schedule posts. Save it as a draft and an editor will publish it."
+	}
+	return "Failed to " + action + " post: " + err.Error()
+}
+
+// scheduleTimeLayout is the format submitted by datetime-local inputs
+const scheduleTimeLayout = "2006-01-02T15:04"
+
+// parseScheduledAt reads the publish time for the "schedule" action. The editor sends the
+// browser's offset from UTC in minutes (as returned by Date.getTimezoneOffset) so the time
+// is interpreted in the author's time zone rather than the server's.
+func parseScheduledAt(r *http.Request, action string) (*time.Time, error) {
+	if action != "schedule" {
+		return nil, nil
+	}
+
+	value := r.FormValue("publish_at")
+	if value == "" {
+		return nil, errors.New("choose a date and time to schedule the post")
+	}
+
+	loc := time.Local
+	if offset, err := strconv.Atoi(r.FormValue("tz_offset")); err == nil {
+		loc = time.FixedZone("", -offset*60)
+	}
+
+	scheduledAt, err := time.ParseInLocation(scheduleTimeLayout, value, loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid publish time %q", value)
+	}
+	return &scheduledAt, nil
+}
+
 // HandleUpdatePost processes the edit post form submission
 func (h *AdminHandlers) HandleUpdatePost() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Get post ID from URL
-		idStr := chi.URLParam(r, "id")
-		id, err := strconv.ParseInt(idStr, 10, 64)
-		if err != nil {
-			http.Error(w, "Invalid post ID", http.StatusBadRequest)
-			return
-		}
-
 		// Parse form
 		if err := r.ParseForm(); err != nil {
 			h.logger.Error("Error parsing form:", err)
@@ -265,21 +325,17 @@ func (h *AdminHandlers) HandleUpdatePost() http.HandlerFunc {
 		}
 
 		// Get existing post
-		existingPost, err := h.posts.GetPostByID(r.Context(), id)
-		if err != nil {
-			h.logger.Error("Error fetching post:", err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
-		}
-		if existingPost == nil {
-			http.NotFound(w, r)
+		existingPost, ok := h.editablePost(w, r)
+		if !ok {
 			return
 		}
 
-		// Get action (draft or publish)
+		// Get action (draft, schedule or publish)
 		action := r.FormValue("action")
 		published := action == "publish"
 
+		scheduledAt, scheduleErr := parseScheduledAt(r, action)
+
 		// Get selected tag IDs
 		var tagIDs []int64
 		for _, idStr := range r.Form["tags[]"] {
@@ -293,9 +349,11 @@ func (h *AdminHandlers) HandleUpdatePost() http.HandlerFunc {
 		// Update post fields
 		post := existingPost
 		post.Title = r.FormValue("title")
+		post.Slug = r.FormValue("slug")
 		post.Content = r.FormValue("content")
 		post.Description = r.FormValue("description")
 		post.CoverImage = r.FormValue("cover_image")
+		post.HideTOC = r.FormValue("hide_toc") == "on"
 
 		// Handle publication status change
 		if published && !post.Published {
@@ -307,18 +365,27 @@ func (h *AdminHandlers) HandleUpdatePost() http.HandlerFunc {
 			// Post is being unpublished
 			post.Published = false
 		}
+		// Saving as a draft cancels any pending schedule
+		post.ScheduledAt = scheduledAt
 
 		// Save updates
-		err = h.posts.UpdatePost(r.Context(), post, tagIDs)
+		err := scheduleErr
+		if err == nil && action != "draft" && !canPublish(r) {
+			err = errCannotPublish
+		}
+		if err == nil {
+			err = h.posts.UpdatePost(r.Context(), post, tagIDs, revisionInfo(r))
+		}
 		if err != nil {
 			h.logger.Error("Error updating post:", err)
 			// Re-render form with error
 			tags, _ := h.posts.ListTags(r.Context())
 			data := admin.PostEditorData{
-				Post:  post,
-				Tags:  tags,
-				IsNew: false,
-				Error: "Failed to update post: " + err.Error(),
+				Post:       post,
+				Tags:       tags,
+				IsNew:      false,
+				Error:      postFormError("update", err),
+				CanPublish: canPublish(r),
 			}
 			admin.PostEditor(data).Render(r.Context(), w)
 			return
@@ -331,17 +398,13 @@ func (h *AdminHandlers) HandleUpdatePost() http.HandlerFunc {
 
 func (h *AdminHandlers) HandleDeletePost() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Get post ID from URL
-		idStr := chi.URLParam(r, "id")
-		id, err := strconv.ParseInt(idStr, 10, 64)
-		if err != nil {
-			h.logger.Error("Invalid post ID:", err)
-			http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		post, ok := h.editablePost(w, r)
+		if !ok {
 			return
 		}
 
 		// Delete post
-		err = h.posts.DeletePost(r.Context(), id)
+		err := h.posts.DeletePost(r.Context(), post.ID)
 		if err != nil {
 			h.logger.Error("Error deleting post:", err)
 			http.Error(w, "Failed to delete post", http.StatusInternalServerError)
@@ -353,7 +416,314 @@ func (h *AdminHandlers) HandleDeletePost() http.HandlerFunc {
 	}
 }
 
-// Inside internal/handlers/admin_handler.go
+// HandleCreatePreviewLink issues a share link for an unpublished post and returns the updated link list
+func (h *AdminHandlers) HandleCreatePreviewLink() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		post, ok := h.editablePost(w, r)
+		if !ok {
+			return
+		}
+
+		var formError string
+		ttl, err := time.ParseDuration(r.FormValue("duration"))
+		if err != nil {
+			formError = "Choose how long the link should work."
+		} else if _, err := h.previews.CreateLink(r.Context(), post, ttl); err != nil {
+			if !errors.Is(err, service.ErrInvalidPreviewTTL) {
+				h.logger.Error("Error creating preview link:", err)
+				http.Error(w, "Failed to create preview link", http.StatusInternalServerError)
+				return
+			}
+			formError = err.Error()
+		}
+
+		h.renderPreviewLinks(w, r, post, formError)
+	}
+}
+
+// HandleRevokePreviewLink revokes a share link and returns the updated link list
+func (h *AdminHandlers) HandleRevokePreviewLink() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		post, ok := h.editablePost(w, r)
+		if !ok {
+			return
+		}
+
+		err := h.previews.RevokeLink(r.Context(), post.ID, chi.URLParam(r, "linkID"))
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			h.logger.Error("Error revoking preview link:", err)
+			http.Error(w, "Failed to revoke preview link", http.StatusInternalServerError)
+			return
+		}
+
+		h.renderPreviewLinks(w, r, post, "")
+	}
+}
+
+// editablePost loads the post named in the URL, answering 404 when there is none and
+// 403 when the signed in user may not change it
+func (h *AdminHandlers) editablePost(w http.ResponseWriter, r *http.Request) (*models.Post, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	post, err := h.posts.GetPostByID(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Error fetching post:", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if post == nil {
+		http.NotFound(w, r)
+		return nil, false
+	}
+	if !canEdit(r, post) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return nil, false
+	}
+
+	return post, true
+}
+
+// canEdit reports whether the signed in user may change post
+func canEdit(r *http.Request, post *models.Post) bool {
+	user := middleware.GetUserFromContext(r.Context())
+	return user != nil && models.CanEditPost(user.ID, user.Role, post)
+}
+
+// canPublish reports whether the signed in user may publish or schedule posts
+func canPublish(r *http.Request) bool {
+	user := middleware.GetUserFromContext(r.Context())
+	return user != nil && models.CanPublish(user.Role)
+}
+
+// creditAuthor attributes a new post to the signed in user unless it already names an author
+func creditAuthor(r *http.Request, post *models.Post) {
+	if user := middleware.GetUserFromContext(r.Context()); user != nil && post.AuthorID == nil {
+		authorID := user.ID
+		post.AuthorID = &authorID
+	}
+}
+
+// revisionInfo credits the signed in user with the revision a save creates
+func revisionInfo(r *http.Request) models.RevisionInfo {
+	if user := middleware.GetUserFromContext(r.Context()); user != nil {
+		return models.RevisionInfo{Author: user.Username}
+	}
+	return models.RevisionInfo{}
+}
+
+// ownPostsOnly returns the signed in user's ID when their role limits them to their
+// own posts, for PostFilter.AuthorID, and 0 when they may see everyone's
+func ownPostsOnly(r *http.Request) int64 {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || models.CanEditAllPosts(user.Role) {
+		return 0
+	}
+	return user.ID
+}
+
+func (h *AdminHandlers) renderPreviewLinks(w http.ResponseWriter, r *http.Request, post *models.Post, formError string) {
+	links, err := h.previews.ListLinks(r.Context(), post)
+	if err != nil {
+		h.logger.Error("Error fetching preview links:", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := admin.PreviewLinks(post, links, formError).Render(r.Context(), w); err != nil {
+		h.logger.Error("Error rendering preview links:", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// ShowTrash lists trashed posts with options to restore or purge them
+func (h *AdminHandlers) ShowTrash() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		posts, err := h.posts.ListTrash(ctx, ownPostsOnly(r))
+		if err != nil {
+			h.logger.Error("Error fetching trash:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		data := admin.TrashData{
+			Posts:         posts,
+			RetentionDays: h.config.Content.TrashRetentionDays,
+		}
+
+		if err := admin.Trash(data).Render(ctx, w); err != nil {
+			h.logger.Error("Error rendering trash page:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	}
+}
+
+// HandleRestorePost moves a post out of the trash
+func (h *AdminHandlers) HandleRestorePost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		post, ok := h.editablePost(w, r)
+		if !ok {
+			return
+		}
+
+		if err := h.posts.RestorePost(r.Context(), post.ID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				http.NotFound(w, r)
+				return
+			}
+			h.logger.Error("Error restoring post:", err)
+			http.Error(w, "Failed to restore post", http.StatusInternalServerError)
+			return
+		}
+
+		// Return 200 OK - HTMX will handle removing the element from the DOM
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// HandlePurgePost permanently deletes a trashed post
+func (h *AdminHandlers) HandlePurgePost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid post ID", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.posts.PurgePost(r.Context(), id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				http.NotFound(w, r)
+				return
+			}
+			h.logger.Error("Error purging post:", err)
+			http.Error(w, "Failed to purge post", http.StatusInternalServerError)
+			return
+		}
+
+		// Return 200 OK - HTMX will handle removing the element from the DOM
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// ShowRevisions displays a post's revision history with a diff between two revisions
+func (h *AdminHandlers) ShowRevisions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		post, ok := h.editablePost(w, r)
+		if !ok {
+			return
+		}
+
+		revisions, err := h.posts.ListRevisions(ctx, post.ID)
+		if err != nil {
+			h.logger.Error("Error fetching revisions:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		data := admin.RevisionsData{
+			Post:      post,
+			Revisions: revisions,
+		}
+		if r.URL.Query().Get("success") == "restored" {
+			data.Success = "Revision restored."
+		}
+
+		// Compare the latest two revisions unless others were picked
+		if len(revisions) > 1 {
+			data.To = &revisions[0]
+			data.From = &revisions[1]
+		}
+		if rev := findRevision(revisions, r.URL.Query().Get("to")); rev != nil {
+			data.To = rev
+		}
+		if rev := findRevision(revisions, r.URL.Query().Get("from")); rev != nil {
+			data.From = rev
+		}
+		if data.From != nil && data.To != nil {
+			data.Diff = utils.DiffLines(data.From.Content, data.To.Content)
+		}
+
+		err = admin.Revisions(data).Render(ctx, w)
+		if err != nil {
+			h.logger.Error("Error rendering revisions page:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	}
+}
+
+// HandleRestoreRevision restores a post to an earlier revision
+func (h *AdminHandlers) HandleRestoreRevision() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		revisionID, err := strconv.ParseInt(chi.URLParam(r, "revisionID"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid revision ID", http.StatusBadRequest)
+			return
+		}
+
+		post, ok := h.editablePost(w, r)
+		if !ok {
+			return
+		}
+		// Restoring keeps the post's published state, so like saving it needs a role
+		// that may publish unless the post is still a draft
+		if (post.Published || post.ScheduledAt != nil) && !canPublish(r) {
+			http.Error(w, errCannotPublish.Error(), http.StatusForbidden)
+			return
+		}
+
+		err = h.posts.RestoreRevision(r.Context(), post.ID, revisionID, revisionInfo(r))
+		if errors.Is(err, service.ErrRevisionNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			h.logger.Error("Error restoring revision:", err)
+			http.Error(w, "Failed to restore revision", http.StatusInternalServerError)
+			return
+		}
+
+		http.Redirect(w, r, fmt.Sprintf("/admin/posts/%d/revisions?success=restored", post.ID), http.StatusSeeOther)
+	}
+}
+
+// findRevision looks up a revision by the ID given in a query parameter
+func findRevision(revisions []models.PostRevision, idStr string) *models.PostRevision {
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return nil
+	}
+	for i := range revisions {
+		if revisions[i].ID == id {
+			return &revisions[i]
+		}
+	}
+	return nil
+}
+
+// HandleRerenderPosts re-renders the stored HTML of every post
+func (h *AdminHandlers) HandleRerenderPosts() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		count, err := h.posts.RerenderPosts(r.Context())
+		if err != nil {
+			h.logger.Error("Error re-rendering posts:", err)
+			http.Error(w, "Failed to re-render posts", http.StatusInternalServerError)
+			return
+		}
+
+		h.logger.Info("Re-rendered posts:", count)
+
+		// Return a short status message - HTMX swaps it next to the button
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, "Re-rendered %d posts", count)
+	}
+}
 
 // Inside internal/handlers/admin_handler.go
 
@@ -2,28 +2,56 @@
 package handlers
 
 import (
+	"blog-portfolio/internal/config"
 	"blog-portfolio/internal/logger"
 	"blog-portfolio/internal/middleware"
+	"blog-portfolio/internal/models"
+	"blog-portfolio/internal/service"
 	"blog-portfolio/web/pages"
+	"blog-portfolio/web/pages/admin"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image/png"
 	"net/http"
-	"time"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pquerna/otp"
 )
 
 type AuthHandlers struct {
-	logger *logger.Logger
+	logger      *logger.Logger
+	config      *config.Config
+	sessions    *middleware.Sessions
+	userService *service.UserService
 }
 
-func NewAuthHandlers(logger *logger.Logger) *AuthHandlers {
+func NewAuthHandlers(logger *logger.Logger, cfg *config.Config, sessions *middleware.Sessions, userService *service.UserService) *AuthHandlers {
 	return &AuthHandlers{
-		logger: logger,
+		logger:      logger,
+		config:      cfg,
+		sessions:    sessions,
+		userService: userService,
 	}
 }
 
-// ShowLogin handles displaying the login page
+// ShowLogin handles displaying the login page, or sends the first visitor to setup
 func (h *AuthHandlers) ShowLogin() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		needsSetup, err := h.userService.NeedsSetup(r.Context())
+		if err != nil {
+			h.logger.Error("Error counting users:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if needsSetup {
+			http.Redirect(w, r, "/setup", http.StatusSeeOther)
+			return
+		}
+
 		// Pass empty data since this is just showing the form
-		err := pages.Login(pages.LoginData{}).Render(r.Context(), w)
+		err = pages.Login(pages.LoginData{}).Render(r.Context(), w)
 		if err != nil {
 			h.logger.Error("Error rendering login page:", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -31,62 +59,579 @@ func (h *AuthHandlers) ShowLogin() http.HandlerFunc {
 	}
 }
 
-// HandleLogin processes the login form
+// HandleLogin processes the login form. Accounts with two-factor authentication
+// continue to the second login step instead of being signed in.
 func (h *AuthHandlers) HandleLogin() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		username := r.FormValue("username")
 		password := r.FormValue("password")
 
-		// Use default admin credentials
-		if username == "admin" && password == "admin" {
-			// Create token
-			token, err := middleware.CreateToken(1, username, "admin")
+		user, err := h.userService.Authenticate(r.Context(), username, password)
+		if err != nil {
+			if !errors.Is(err, service.ErrInvalidCredentials) {
+				h.logger.Error("Error checking credentials:", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+
+			// Re-render login page with error
+			w.WriteHeader(http.StatusUnauthorized)
+			err := pages.Login(pages.LoginData{
+				Error: "Invalid username or password",
+			}).Render(r.Context(), w)
 			if err != nil {
+				h.logger.Error("Error rendering login page:", err)
+			}
+			return
+		}
+
+		if user.TwoFactorEnabled() {
+			if err := h.sessions.StartSecondFactor(w, user.ID); err != nil {
 				h.logger.Error("Error creating token:", err)
 				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 				return
 			}
+			http.Redirect(w, r, "/login/2fa", http.StatusSeeOther)
+			return
+		}
 
-			// Set cookie
-			http.SetCookie(w, &http.Cookie{
-				Name:     "session",
-				Value:    token,
-				Path:     "/",
-				Expires:  time.Now().Add(24 * time.Hour),
-				HttpOnly: true,
-				Secure:   true,
-				SameSite: http.SameSiteStrictMode,
-			})
+		h.startSession(w, r, user)
+	}
+}
+
+// ShowSecondFactor displays the second login step, asking for a code from the
+// authenticator app
+func (h *AuthHandlers) ShowSecondFactor() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := h.sessions.SecondFactorUser(r); !ok {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+		h.renderSecondFactor(w, r, pages.SecondFactorData{}, http.StatusOK)
+	}
+}
+
+// HandleSecondFactor checks the code from the second login step and signs the user in
+func (h *AuthHandlers) HandleSecondFactor() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := h.sessions.SecondFactorUser(r)
+		if !ok {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
 
-			// Redirect to admin dashboard
-			http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		user, err := h.userService.VerifySecondFactor(r.Context(), id, r.FormValue("code"))
+		switch {
+		case errors.Is(err, service.ErrInvalidCode):
+			h.renderSecondFactor(w, r, pages.SecondFactorData{Error: "That code isn't valid. Try again."}, http.StatusUnauthorized)
+			return
+		case errors.Is(err, service.ErrTooManyAttempts):
+			h.sessions.ClearSecondFactor(w)
+			w.WriteHeader(http.StatusTooManyRequests)
+			if err := pages.Login(pages.LoginData{Error: "Too many wrong codes. Wait a few minutes and sign in again."}).Render(r.Context(), w); err != nil {
+				h.logger.Error("Error rendering login page:", err)
+			}
+			return
+		case errors.Is(err, service.ErrUserNotFound):
+			h.sessions.ClearSecondFactor(w)
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		case err != nil:
+			h.logger.Error("Error checking second factor:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			return
 		}
 
-		// Re-render login page with error
-		err := pages.Login(pages.LoginData{
-			Error: "Invalid username or password",
-		}).Render(r.Context(), w)
+		h.sessions.ClearSecondFactor(w)
+		h.startSession(w, r, user)
+	}
+}
+
+func (h *AuthHandlers) renderSecondFactor(w http.ResponseWriter, r *http.Request, data pages.SecondFactorData, status int) {
+	w.WriteHeader(status)
+	if err := pages.SecondFactor(data).Render(r.Context(), w); err != nil {
+		h.logger.Error("Error rendering two-factor page:", err)
+	}
+}
+
+// ShowSetup displays the form for creating the first admin account
+func (h *AuthHandlers) ShowSetup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		needsSetup, err := h.userService.NeedsSetup(r.Context())
 		if err != nil {
-			h.logger.Error("Error rendering login page:", err)
+			h.logger.Error("Error counting users:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if !needsSetup {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+
+		h.renderSetup(w, r, pages.SetupData{}, http.StatusOK)
+	}
+}
+
+// HandleSetup creates the first admin account and signs it in. It is refused once
+// any account exists.
+func (h *AuthHandlers) HandleSetup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username := r.FormValue("username")
+		password := r.FormValue("password")
+		data := pages.SetupData{Username: username}
+
+		if password != r.FormValue("confirm_password") {
+			data.Error = "The passwords don't match"
+			h.renderSetup(w, r, data, http.StatusUnprocessableEntity)
+			return
+		}
+
+		user, err := h.userService.CreateFirstAdmin(r.Context(), username, password)
+		switch {
+		case errors.Is(err, service.ErrSetupComplete):
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		case errors.Is(err, service.ErrInvalidUsername), errors.Is(err, service.ErrWeakPassword):
+			data.Error = err.Error()
+			h.renderSetup(w, r, data, http.StatusUnprocessableEntity)
+			return
+		case err != nil:
+			h.logger.Error("Error creating admin account:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		h.logger.Info("Created admin account", user.Username)
+		h.startSession(w, r, user)
+	}
+}
+
+func (h *AuthHandlers) renderSetup(w http.ResponseWriter, r *http.Request, data pages.SetupData, status int) {
+	data.MinLength = service.MinPasswordLength
+	w.WriteHeader(status)
+	if err := pages.Setup(data).Render(r.Context(), w); err != nil {
+		h.logger.Error("Error rendering setup page:", err)
+	}
+}
+
+// ShowChangePassword displays the change password form
+func (h *AuthHandlers) ShowChangePassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.renderChangePassword(w, r, admin.PasswordData{}, http.StatusOK)
+	}
+}
+
+// HandleChangePassword changes the signed in user's password after checking their
+// current one
+func (h *AuthHandlers) HandleChangePassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := middleware.GetUserFromContext(r.Context())
+		if user == nil {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+
+		password := r.FormValue("new_password")
+		if password != r.FormValue("confirm_password") {
+			h.renderChangePassword(w, r, admin.PasswordData{Error: "The new passwords don't match"}, http.StatusUnprocessableEntity)
+			return
+		}
+
+		err := h.userService.ChangePassword(r.Context(), user.ID, r.FormValue("current_password"), password)
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			h.renderChangePassword(w, r, admin.PasswordData{Error: "The current password is wrong"}, http.StatusUnprocessableEntity)
+			return
+		case errors.Is(err, service.ErrWeakPassword):
+			h.renderChangePassword(w, r, admin.PasswordData{Error: err.Error()}, http.StatusUnprocessableEntity)
+			return
+		case errors.Is(err, service.ErrUserNotFound):
+			h.sessions.ClearCookie(w)
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		case err != nil:
+			h.logger.Error("Error changing password:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		h.renderChangePassword(w, r, admin.PasswordData{Message: "Your password has been changed"}, http.StatusOK)
+	}
+}
+
+func (h *AuthHandlers) renderChangePassword(w http.ResponseWriter, r *http.Request, data admin.PasswordData, status int) {
+	if user := middleware.GetUserFromContext(r.Context()); user != nil {
+		data.Username = user.Username
+	}
+	data.MinLength = service.MinPasswordLength
+	w.WriteHeader(status)
+	if err := admin.ChangePassword(data).Render(r.Context(), w); err != nil {
+		h.logger.Error("Error rendering change password page:", err)
+	}
+}
+
+// ShowProfile displays the signed in user's public author profile
+func (h *AuthHandlers) ShowProfile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := h.currentUser(w, r)
+		if !ok {
+			return
+		}
+		h.renderProfile(w, r, admin.ProfileData{User: user}, http.StatusOK)
+	}
+}
+
+// HandleUpdateProfile saves the signed in user's display name, bio and avatar
+func (h *AuthHandlers) HandleUpdateProfile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := h.currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		displayName := r.FormValue("display_name")
+		bio := r.FormValue("bio")
+		avatar := r.FormValue("avatar")
+
+		updated, err := h.userService.UpdateProfile(r.Context(), user.ID, displayName, bio, avatar)
+		switch {
+		case errors.Is(err, service.ErrInvalidProfile):
+			// Keep what was typed so it can be fixed
+			user.DisplayName, user.Bio, user.Avatar = displayName, bio, avatar
+			h.renderProfile(w, r, admin.ProfileData{User: user, Error: err.Error()}, http.StatusUnprocessableEntity)
+			return
+		case err != nil:
+			h.logger.Error("Error updating profile:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		h.renderProfile(w, r, admin.ProfileData{User: updated, Message: "Your profile has been saved"}, http.StatusOK)
+	}
+}
+
+func (h *AuthHandlers) renderProfile(w http.ResponseWriter, r *http.Request, data admin.ProfileData, status int) {
+	w.WriteHeader(status)
+	if err := admin.Profile(data).Render(r.Context(), w); err != nil {
+		h.logger.Error("Error rendering profile page:", err)
+	}
+}
+
+// currentUser loads the signed in user's account, sending them to the login page if
+// it has been deleted
+func (h *AuthHandlers) currentUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	var user *models.User
+	var err error
+	if signedIn := middleware.GetUserFromContext(r.Context()); signedIn != nil {
+		user, err = h.userService.GetUser(r.Context(), signedIn.ID)
+	}
+	if err != nil {
+		h.logger.Error("Error fetching user:", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if user == nil {
+		h.sessions.ClearCookie(w)
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil, false
+	}
+	return user, true
+}
+
+// ShowUsers lists every account with its role, for admins
+func (h *AuthHandlers) ShowUsers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data := admin.UsersData{}
+		switch r.URL.Query().Get("success") {
+		case "created":
+			data.Message = "User added."
+		case "role":
+			data.Message = "Role changed."
+		case "2fa":
+			data.Message = "Two-factor authentication turned off. The user can sign in with their password and set it up again."
+		}
+		h.renderUsers(w, r, data, http.StatusOK)
+	}
+}
+
+// HandleCreateUser adds an account with the chosen role
+func (h *AuthHandlers) HandleCreateUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := h.userService.CreateUser(r.Context(), r.FormValue("username"), r.FormValue("password"), r.FormValue("role"))
+		switch {
+		case errors.Is(err, service.ErrInvalidUsername), errors.Is(err, service.ErrUsernameTaken),
+			errors.Is(err, service.ErrWeakPassword), errors.Is(err, service.ErrInvalidRole):
+			h.renderUsers(w, r, admin.UsersData{Error: err.Error()}, http.StatusUnprocessableEntity)
+			return
+		case err != nil:
+			h.logger.Error("Error creating user:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		h.logger.Info("Created user", user.Username, "with role", user.Role)
+		http.Redirect(w, r, "/admin/users?success=created", http.StatusSeeOther)
+	}
+}
+
+// HandleChangeRole gives an account another role
+func (h *AuthHandlers) HandleChangeRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		err = h.userService.ChangeRole(r.Context(), id, r.FormValue("role"))
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			http.NotFound(w, r)
+			return
+		case errors.Is(err, service.ErrInvalidRole), errors.Is(err, service.ErrLastAdmin):
+			h.renderUsers(w, r, admin.UsersData{Error: err.Error()}, http.StatusUnprocessableEntity)
+			return
+		case err != nil:
+			h.logger.Error("Error changing role:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		http.Redirect(w, r, "/admin/users?success=role", http.StatusSeeOther)
+	}
+}
+
+// HandleResetTwoFactor turns off two-factor authentication for an account that has
+// lost its authenticator and recovery codes
+func (h *AuthHandlers) HandleResetTwoFactor() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		user, err := h.userService.GetUser(r.Context(), id)
+		if err == nil && user == nil {
+			err = service.ErrUserNotFound
+		}
+		if err == nil {
+			err = h.userService.ResetTOTP(r.Context(), user.Username)
+		}
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			http.NotFound(w, r)
+			return
+		case errors.Is(err, service.ErrTwoFactorDisabled):
+			h.renderUsers(w, r, admin.UsersData{Error: err.Error()}, http.StatusUnprocessableEntity)
+			return
+		case err != nil:
+			h.logger.Error("Error resetting two-factor authentication:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		h.logger.Info("Reset two-factor authentication of", user.Username)
+		http.Redirect(w, r, "/admin/users?success=2fa", http.StatusSeeOther)
+	}
+}
+
+func (h *AuthHandlers) renderUsers(w http.ResponseWriter, r *http.Request, data admin.UsersData, status int) {
+	users, err := h.userService.ListUsers(r.Context())
+	if err != nil {
+		h.logger.Error("Error listing users:", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	data.Users = users
+	data.MinLength = service.MinPasswordLength
+	w.WriteHeader(status)
+	if err := admin.Users(data).Render(r.Context(), w); err != nil {
+		h.logger.Error("Error rendering users page:", err)
+	}
+}
+
+// ShowTwoFactor displays the signed in user's two-factor settings, with a secret to
+// scan while it is off
+func (h *AuthHandlers) ShowTwoFactor() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := h.currentUser(w, r)
+		if !ok {
+			return
+		}
+		h.renderTwoFactor(w, r, user, admin.TwoFactorData{}, http.StatusOK)
+	}
+}
+
+// HandleEnableTwoFactor turns on two-factor authentication once the user enters their
+// password and a code for the secret they scanned, and shows their recovery codes
+func (h *AuthHandlers) HandleEnableTwoFactor() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := h.currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		codes, err := h.userService.EnableTOTP(r.Context(), user.ID, r.FormValue("enable_password"), r.FormValue("code"))
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			h.renderTwoFactor(w, r, user, admin.TwoFactorData{Error: "The password is wrong"}, http.StatusUnprocessableEntity)
+			return
+		case errors.Is(err, service.ErrInvalidCode):
+			data := admin.TwoFactorData{Error: "That code doesn't match. Check the time on your phone and try the next code."}
+			h.renderTwoFactor(w, r, user, data, http.StatusUnprocessableEntity)
+			return
+		case errors.Is(err, service.ErrEnrollmentExpired):
+			h.renderTwoFactor(w, r, user, admin.TwoFactorData{Error: "The setup expired. Scan the new QR code and try again."}, http.StatusUnprocessableEntity)
+			return
+		case errors.Is(err, service.ErrTwoFactorEnabled):
+			http.Redirect(w, r, "/admin/account/2fa", http.StatusSeeOther)
+			return
+		case err != nil:
+			h.logger.Error("Error enabling two-factor authentication:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		if user, err = h.userService.GetUser(r.Context(), user.ID); err != nil || user == nil {
+			h.logger.Error("Error fetching user:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		data := admin.TwoFactorData{
+			Message:       "Two-factor authentication is on.",
+			RecoveryCodes: codes,
+		}
+		h.renderTwoFactor(w, r, user, data, http.StatusOK)
+	}
+}
+
+// HandleDisableTwoFactor turns off two-factor authentication after checking the
+// user's password
+func (h *AuthHandlers) HandleDisableTwoFactor() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := h.currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		err := h.userService.DisableTOTP(r.Context(), user.ID, r.FormValue("disable_password"))
+		if h.twoFactorChangeFailed(w, r, user, err) {
+			return
+		}
+
+		user.TOTPSecret = ""
+		h.renderTwoFactor(w, r, user, admin.TwoFactorData{Message: "Two-factor authentication is off."}, http.StatusOK)
+	}
+}
+
+// HandleRegenerateRecoveryCodes replaces the user's recovery codes after checking
+// their password, and shows the new ones
+func (h *AuthHandlers) HandleRegenerateRecoveryCodes() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := h.currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		codes, err := h.userService.RegenerateRecoveryCodes(r.Context(), user.ID, r.FormValue("regenerate_password"))
+		if h.twoFactorChangeFailed(w, r, user, err) {
+			return
+		}
+
+		data := admin.TwoFactorData{
+			Message:       "Your old recovery codes no longer work.",
+			RecoveryCodes: codes,
+		}
+		h.renderTwoFactor(w, r, user, data, http.StatusOK)
+	}
+}
+
+// twoFactorChangeFailed answers a failed change to two-factor settings, reporting
+// whether there was one
+func (h *AuthHandlers) twoFactorChangeFailed(w http.ResponseWriter, r *http.Request, user *models.User, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, service.ErrInvalidCredentials):
+		h.renderTwoFactor(w, r, user, admin.TwoFactorData{Error: "The password is wrong"}, http.StatusUnprocessableEntity)
+	case errors.Is(err, service.ErrTwoFactorDisabled):
+		http.Redirect(w, r, "/admin/account/2fa", http.StatusSeeOther)
+	default:
+		h.logger.Error("Error changing two-factor authentication:", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+	return true
+}
+
+// renderTwoFactor renders the two-factor settings of user, offering their pending
+// secret to scan while it is off
+func (h *AuthHandlers) renderTwoFactor(w http.ResponseWriter, r *http.Request, user *models.User, data admin.TwoFactorData, status int) {
+	data.Enabled = user.TwoFactorEnabled()
+	if data.Enabled {
+		left, err := h.userService.CountRecoveryCodes(r.Context(), user.ID)
+		if err != nil {
+			h.logger.Error("Error counting recovery codes:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		data.RecoveryCodesLeft = left
+	} else {
+		key, err := h.userService.PendingTOTPKey(user, h.config.App.Title)
+		if err == nil {
+			data.QRCode, err = qrCodeDataURL(key)
+		}
+		if err != nil {
+			h.logger.Error("Error generating two-factor secret:", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
 		}
+		data.Secret = key.Secret()
 	}
+
+	// The page can hold a secret or recovery codes, so it must never be cached
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := admin.TwoFactor(data).Render(r.Context(), w); err != nil {
+		h.logger.Error("Error rendering two-factor page:", err)
+	}
+}
+
+// qrCodeDataURL renders key as a QR code for authenticator apps to scan, inlined as a
+// data URL so the secret never appears in a request URL or log
+func qrCodeDataURL(key *otp.Key) (string, error) {
+	img, err := key.Image(200, 200)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// startSession signs the user in and sends them to the dashboard
+func (h *AuthHandlers) startSession(w http.ResponseWriter, r *http.Request, user *models.User) {
+	token, err := h.sessions.CreateToken(user.ID, user.Username, user.Role)
+	if err != nil {
+		h.logger.Error("Error creating token:", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	// Set cookie
+	h.sessions.SetCookie(w, token)
+
+	// Redirect to admin dashboard
+	http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
 }
 
 // HandleLogout logs out the user
 func (h *AuthHandlers) HandleLogout() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Clear the session cookie
-		http.SetCookie(w, &http.Cookie{
-			Name:     "session",
-			Value:    "",
-			Path:     "/",
-			Expires:  time.Now().Add(-1 * time.Hour),
-			HttpOnly: true,
-			Secure:   true,
-			SameSite: http.SameSiteStrictMode,
-		})
+		h.sessions.ClearCookie(w)
 
 		// Redirect to login page
 		http.Redirect(w, r, "/login", http.StatusSeeOther)
@@ -0,0 +1,95 @@
+// internal/handlers/backup_handler.go
+package handlers
+
+import (
+	"blog-portfolio/internal/backup"
+	"blog-portfolio/web/pages/admin"
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ShowBackups lists the database snapshots
+func (h *AdminHandlers) ShowBackups() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := h.backupsData()
+		if err != nil {
+			h.logger.Error("Error listing backups:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		if err := admin.Backups(data).Render(r.Context(), w); err != nil {
+			h.logger.Error("Error rendering backups page:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	}
+}
+
+// HandleCreateBackup takes a snapshot on demand and returns the updated list
+func (h *AdminHandlers) HandleCreateBackup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		snapshot, backupErr := h.backups.Create()
+		if backupErr != nil {
+			h.logger.Error("Error backing up database:", backupErr)
+		}
+
+		data, err := h.backupsData()
+		if err != nil {
+			h.logger.Error("Error listing backups:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if backupErr != nil {
+			data.Error = "Backup failed: " + backupErr.Error()
+		} else {
+			data.Message = "Backed up to " + snapshot.Name
+		}
+
+		if err := admin.BackupList(data).Render(r.Context(), w); err != nil {
+			h.logger.Error("Error rendering backups:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	}
+}
+
+// HandleDownloadBackup sends a snapshot as a file download
+func (h *AdminHandlers) HandleDownloadBackup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		snapshot, err := h.backups.Find(chi.URLParam(r, "name"))
+		if err != nil {
+			if !errors.Is(err, backup.ErrNotFound) {
+				h.logger.Error("Error finding backup:", err)
+			}
+			http.NotFound(w, r)
+			return
+		}
+
+		file, err := os.Open(snapshot.Path)
+		if err != nil {
+			h.logger.Error("Error opening backup:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		defer file.Close()
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+snapshot.Name+`"`)
+		w.Header().Set("Cache-Control", "no-store")
+		http.ServeContent(w, r, snapshot.Name, snapshot.CreatedAt, file)
+	}
+}
+
+func (h *AdminHandlers) backupsData() (admin.BackupsData, error) {
+	snapshots, err := h.backups.List()
+	if err != nil {
+		return admin.BackupsData{}, err
+	}
+	return admin.BackupsData{
+		Snapshots:     snapshots,
+		IntervalHours: h.config.Backup.IntervalHours,
+		Keep:          h.config.Backup.Keep,
+	}, nil
+}
@@ -0,0 +1,188 @@
+// internal/handlers/feed_handler.go
+package handlers
+
+import (
+	"blog-portfolio/internal/config"
+	"blog-portfolio/internal/feed"
+	"blog-portfolio/internal/logger"
+	"blog-portfolio/internal/models"
+	"blog-portfolio/internal/service"
+	"blog-portfolio/internal/utils"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// feedExcerptLength is the length of item summaries built from post content
+const feedExcerptLength = 300
+
+// FeedHandlers serves the site's RSS, Atom and JSON feeds. Each is also available per
+// tag under /tags/{slug}/.
+type FeedHandlers struct {
+	logger *logger.Logger
+	config *config.Config
+	posts  *service.PostService
+	tags   *service.TagService
+}
+
+func NewFeedHandlers(logger *logger.Logger, cfg *config.Config, postService *service.PostService, tagService *service.TagService) *FeedHandlers {
+	return &FeedHandlers{
+		logger: logger,
+		config: cfg,
+		posts:  postService,
+		tags:   tagService,
+	}
+}
+
+// RSS serves the RSS 2.0 feed
+func (h *FeedHandlers) RSS() http.HandlerFunc {
+	return h.serve("application/rss+xml; charset=utf-8", (*feed.Feed).WriteRSS)
+}
+
+// Atom serves the Atom feed
+func (h *FeedHandlers) Atom() http.HandlerFunc {
+	return h.serve("application/atom+xml; charset=utf-8", (*feed.Feed).WriteAtom)
+}
+
+// JSON serves the JSON Feed
+func (h *FeedHandlers) JSON() http.HandlerFunc {
+	return h.serve("application/feed+json; charset=utf-8", (*feed.Feed).WriteJSON)
+}
+
+// serve builds the feed for the request and writes it with encode. The response
+// carries an ETag and Last-Modified, so readers polling an unchanged feed get a 304.
+func (h *FeedHandlers) serve(contentType string, encode func(*feed.Feed, io.Writer) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f, err := h.build(r)
+		if err != nil {
+			h.logger.Error("Error building feed:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if f == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := encode(f, &buf); err != nil {
+			h.logger.Error("Error encoding feed:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		sum := sha256.Sum256(buf.Bytes())
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+		http.ServeContent(w, r, "", f.Updated, bytes.NewReader(buf.Bytes()))
+	}
+}
+
+// build assembles the feed for the request, returning nil for an unknown tag
+func (h *FeedHandlers) build(r *http.Request) (*feed.Feed, error) {
+	ctx := r.Context()
+	baseURL := strings.TrimSuffix(h.config.App.BaseURL, "/")
+
+	f := &feed.Feed{
+		Title:       h.config.App.Title,
+		Description: h.config.App.Description,
+		HomeURL:     baseURL + "/blog",
+		FeedURL:     baseURL + r.URL.Path,
+		Author:      h.config.App.Author,
+		Language:    "en",
+	}
+
+	published := true
+	filter := models.PostFilter{
+		Published: &published,
+		WithTags:  true,
+		Limit:     h.config.Feed.Items,
+	}
+
+	if slug := chi.URLParam(r, "slug"); slug != "" {
+		tag, err := h.tags.GetTagBySlug(ctx, slug)
+		if err != nil {
+			return nil, err
+		}
+		if tag == nil {
+			return nil, nil
+		}
+		filter.Tag = tag.Slug
+		f.Title += " – " + tag.Name
+		f.HomeURL += "?tag=" + url.QueryEscape(tag.Slug)
+	}
+
+	posts, err := h.posts.ListPosts(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, post := range posts {
+		item := feedItem(post, baseURL, h.config.Feed.FullContent)
+		if item.Updated.After(f.Updated) {
+			f.Updated = item.Updated
+		}
+		f.Items = append(f.Items, item)
+	}
+
+	return f, nil
+}
+
+// feedItem converts a published post to a feed item with absolute URLs
+func feedItem(post *models.Post, baseURL string, fullContent bool) feed.Item {
+	link := baseURL + "/blog/" + url.PathEscape(post.Slug)
+
+	published := post.CreatedAt
+	if post.PublishedAt != nil {
+		published = *post.PublishedAt
+	}
+	// A post can be published after its last edit
+	updated := post.UpdatedAt
+	if published.After(updated) {
+		updated = published
+	}
+
+	summary := post.Description
+	if summary == "" {
+		summary = utils.Excerpt(post.ContentHTML, feedExcerptLength)
+	}
+
+	item := feed.Item{
+		ID:        link,
+		Title:     post.Title,
+		URL:       link,
+		Summary:   summary,
+		Published: published,
+		Updated:   updated.Truncate(time.Second),
+	}
+	if fullContent {
+		item.ContentHTML = feed.AbsoluteURLs(post.ContentHTML, baseURL)
+	}
+	if post.CoverImage != "" {
+		item.Image = absoluteURL(post.CoverImage, baseURL)
+	}
+	if post.Author != nil {
+		item.Author = post.Author.Name()
+	}
+	for _, tag := range post.Tags {
+		item.Tags = append(item.Tags, tag.Name)
+	}
+
+	return item
+}
+
+// absoluteURL resolves a root-relative URL against the site's base URL
+func absoluteURL(u, baseURL string) string {
+	if strings.HasPrefix(u, "/") && !strings.HasPrefix(u, "//") {
+		return baseURL + u
+	}
+	return u
+}
@@ -2,31 +2,51 @@
 package handlers
 
 import (
+	"blog-portfolio/internal/backup"
+	"blog-portfolio/internal/config"
 	"blog-portfolio/internal/logger"
 	"blog-portfolio/internal/middleware"
 	"blog-portfolio/internal/models"
+	"blog-portfolio/internal/ogimage"
 	"blog-portfolio/internal/service"
-	"blog-portfolio/web/layouts"
+	"blog-portfolio/internal/storage"
+	"blog-portfolio/internal/utils"
 	"blog-portfolio/web/pages"
+	"context"
+	"errors"
 	"net/http"
+	"path"
+
+	"github.com/go-chi/chi/v5"
 )
 
 type Handlers struct {
-	logger      *logger.Logger
-	posts       *PostHandlers
-	auth        *AuthHandlers
-	admin       *AdminHandlers
-	postService *service.PostService
+	logger       *logger.Logger
+	config       *config.Config
+	posts        *PostHandlers
+	auth         *AuthHandlers
+	sessions     *middleware.Sessions
+	admin        *AdminHandlers
+	feeds        *FeedHandlers
+	seo          *SEOHandlers
+	postService  *service.PostService
+	mediaService *service.MediaService
 }
 
 // New creates a new instance of Handlers
-func New(logger *logger.Logger, postService *service.PostService, tagService *service.TagService) *Handlers {
+func New(logger *logger.Logger, cfg *config.Config, postService *service.PostService, tagService *service.TagService, previewService *service.PreviewService, mediaService *service.MediaService, userService *service.UserService, backups *backup.Manager, ogImages *ogimage.Renderer) *Handlers {
+	sessions := middleware.NewSessions(cfg.Auth, sessionUserLoader(userService))
 	return &Handlers{
-		logger:      logger,
-		posts:       NewPostHandlers(postService, logger),
-		auth:        NewAuthHandlers(logger),
-		admin:       NewAdminHandlers(logger, postService, tagService), // Pass tagService here
-		postService: postService,
+		logger:       logger,
+		config:       cfg,
+		posts:        NewPostHandlers(postService, previewService, userService, logger, cfg),
+		auth:         NewAuthHandlers(logger, cfg, sessions, userService),
+		sessions:     sessions,
+		admin:        NewAdminHandlers(logger, cfg, postService, tagService, previewService, mediaService, backups), // Pass tagService here
+		feeds:        NewFeedHandlers(logger, cfg, postService, tagService),
+		seo:          NewSEOHandlers(logger, cfg, postService, tagService, ogImages),
+		postService:  postService,
+		mediaService: mediaService,
 	}
 }
 
@@ -40,11 +60,37 @@ func (h *Handlers) Auth() *AuthHandlers {
 	return h.auth
 }
 
+// Sessions returns the session cookies shared by the login handlers and the admin routes
+func (h *Handlers) Sessions() *middleware.Sessions {
+	return h.sessions
+}
+
+// sessionUserLoader loads signed in accounts for the sessions middleware
+func sessionUserLoader(users *service.UserService) middleware.UserLoader {
+	return func(ctx context.Context, id int64) (*middleware.User, error) {
+		user, err := users.GetUser(ctx, id)
+		if err != nil || user == nil {
+			return nil, err
+		}
+		return &middleware.User{ID: user.ID, Username: user.Username, Role: user.Role}, nil
+	}
+}
+
 // Admin returns the admin handlers
 func (h *Handlers) Admin() *AdminHandlers {
 	return h.admin
 }
 
+// Feeds returns the feed handlers
+func (h *Handlers) Feeds() *FeedHandlers {
+	return h.feeds
+}
+
+// SEO returns the robots.txt, sitemap and share image handlers
+func (h *Handlers) SEO() *SEOHandlers {
+	return h.seo
+}
+
 // Home handles the home page
 func (h *Handlers) Home() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -62,13 +108,60 @@ func (h *Handlers) Home() http.HandlerFunc {
 		}
 
 		// Pass data to template
-		if err := pages.Home(layouts.PageData{
-			Title:       "Amogh's Eden",
-			Description: "Welcome to my personal blog and portfolio",
-			IsAdmin:     middleware.IsAdmin(r),
-		}, latestPosts).Render(ctx, w); err != nil {
+		data := pageData(h.config, r, "Amogh's Eden", "Welcome to my personal blog and portfolio", "/")
+		if err := pages.Home(data, latestPosts).Render(ctx, w); err != nil {
 			h.logger.Error("Error rendering home page:", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		}
 	}
 }
+
+// HighlightCSS serves the stylesheet for syntax highlighted code blocks
+func (h *Handlers) HighlightCSS() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/css; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		w.Write([]byte(utils.HighlightCSS()))
+	}
+}
+
+// Media serves uploaded images. Backends with their own URLs, such as S3, get a
+// redirect; local files are served directly. Stored names are random and never
+// reused, so local files can be cached indefinitely.
+func (h *Handlers) Media() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		name := chi.URLParam(r, "*")
+
+		url, err := h.mediaService.FileURL(ctx, name)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotExist) {
+				http.NotFound(w, r)
+				return
+			}
+			h.logger.Error("Error building media URL:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if url != "" {
+			// Signed URLs expire, so the redirect itself must not be cached for long
+			w.Header().Set("Cache-Control", "public, max-age=300")
+			http.Redirect(w, r, url, http.StatusFound)
+			return
+		}
+
+		file, err := h.mediaService.Open(ctx, name)
+		if err != nil {
+			if !errors.Is(err, storage.ErrNotExist) {
+				h.logger.Error("Error opening media:", err)
+			}
+			http.NotFound(w, r)
+			return
+		}
+		defer file.Close()
+
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		http.ServeContent(w, r, path.Base(name), file.ModTime(), file)
+	}
+}
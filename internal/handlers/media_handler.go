@@ -0,0 +1,234 @@
+// internal/handlers/media_handler.go
+package handlers
+
+import (
+	"blog-portfolio/internal/models"
+	"blog-portfolio/internal/service"
+	"blog-portfolio/web/pages/admin"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// mediaPageSize is how many uploads the library and picker show at once
+	mediaPageSize = 60
+
+	// maxUploadFiles is how many files a single upload request may contain
+	maxUploadFiles = 10
+)
+
+// ShowMedia handles the media library page. HTMX searches only get the grid back.
+func (h *AdminHandlers) ShowMedia() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		query := r.URL.Query().Get("q")
+		items, err := h.media.ListMedia(ctx, query, mediaPageSize, 0)
+		if err != nil {
+			h.logger.Error("Error fetching media:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		data := admin.MediaGridData{
+			Items:  items,
+			Query:  query,
+			Picker: r.URL.Query().Get("picker") == "1",
+		}
+
+		switch {
+		case r.Header.Get("HX-Request") == "true":
+			err = admin.MediaGrid(data).Render(ctx, w)
+		case r.Header.Get("Accept") == "application/json":
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(items); err != nil {
+				h.logger.Error("Error encoding media:", err)
+				http.Error(w, "Error encoding response", http.StatusInternalServerError)
+			}
+			return
+		default:
+			err = admin.MediaLibrary(data, h.config.Media.MaxUploadMB).Render(ctx, w)
+		}
+
+		if err != nil {
+			h.logger.Error("Error rendering media library:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	}
+}
+
+// ShowMediaPicker renders the media picker loaded into the post editor
+func (h *AdminHandlers) ShowMediaPicker() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		items, err := h.media.ListMedia(ctx, "", mediaPageSize, 0)
+		if err != nil {
+			h.logger.Error("Error fetching media:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		data := admin.MediaGridData{
+			Items:  items,
+			Picker: true,
+		}
+		if err := admin.MediaPicker(data).Render(ctx, w); err != nil {
+			h.logger.Error("Error rendering media picker:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	}
+}
+
+// HandleUploadMedia stores the images in a multipart "files" field. Files that are
+// too large or aren't images are reported without failing the rest of the upload.
+func (h *AdminHandlers) HandleUploadMedia() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		maxBytes := h.config.Media.MaxUploadBytes()
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes*maxUploadFiles+1<<20)
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			http.Error(w, "Upload too large or malformed", http.StatusRequestEntityTooLarge)
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		files := r.MultipartForm.File["files"]
+		if len(files) == 0 {
+			http.Error(w, "No files uploaded", http.StatusBadRequest)
+			return
+		}
+		if len(files) > maxUploadFiles {
+			http.Error(w, fmt.Sprintf("Upload at most %d files at a time", maxUploadFiles), http.StatusBadRequest)
+			return
+		}
+
+		alt := r.FormValue("alt_text")
+		var uploaded []*models.Media
+		var problems []string
+		for _, fh := range files {
+			if fh.Size > maxBytes {
+				problems = append(problems, fmt.Sprintf("%s: larger than %d MB", fh.Filename, h.config.Media.MaxUploadMB))
+				continue
+			}
+
+			file, err := fh.Open()
+			if err != nil {
+				h.logger.Error("Error opening upload:", err)
+				http.Error(w, "Failed to read upload", http.StatusInternalServerError)
+				return
+			}
+			data, err := io.ReadAll(file)
+			file.Close()
+			if err != nil {
+				h.logger.Error("Error reading upload:", err)
+				http.Error(w, "Failed to read upload", http.StatusInternalServerError)
+				return
+			}
+
+			media, err := h.media.Upload(ctx, fh.Filename, data, alt)
+			if err != nil {
+				if errors.Is(err, service.ErrUnsupportedMediaType) || errors.Is(err, service.ErrImageTooLarge) {
+					problems = append(problems, fh.Filename+": "+err.Error())
+					continue
+				}
+				h.logger.Error("Error storing upload:", err)
+				http.Error(w, "Failed to store upload", http.StatusInternalServerError)
+				return
+			}
+			uploaded = append(uploaded, media)
+		}
+
+		switch {
+		case r.Header.Get("HX-Request") == "true":
+			items, err := h.media.ListMedia(ctx, "", mediaPageSize, 0)
+			if err != nil {
+				h.logger.Error("Error fetching media:", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			data := admin.MediaGridData{
+				Items:  items,
+				Error:  strings.Join(problems, "; "),
+				Picker: r.FormValue("picker") == "1",
+			}
+			if err := admin.MediaGrid(data).Render(ctx, w); err != nil {
+				h.logger.Error("Error rendering media grid:", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+		case r.Header.Get("Accept") == "application/json":
+			status := http.StatusCreated
+			if len(uploaded) == 0 {
+				status = http.StatusBadRequest
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(struct {
+				Media  []*models.Media `json:"media"`
+				Errors []string        `json:"errors,omitempty"`
+			}{uploaded, problems})
+		default:
+			if len(uploaded) == 0 {
+				http.Error(w, strings.Join(problems, "\n"), http.StatusBadRequest)
+				return
+			}
+			http.Redirect(w, r, "/admin/media", http.StatusSeeOther)
+		}
+	}
+}
+
+// HandleUpdateMedia changes an upload's alt text
+func (h *AdminHandlers) HandleUpdateMedia() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid media ID", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.media.UpdateAltText(r.Context(), id, r.FormValue("alt_text")); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				http.NotFound(w, r)
+				return
+			}
+			h.logger.Error("Error updating media:", err)
+			http.Error(w, "Failed to update media", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// HandleDeleteMedia deletes an upload and its files
+func (h *AdminHandlers) HandleDeleteMedia() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid media ID", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.media.DeleteMedia(r.Context(), id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				http.NotFound(w, r)
+				return
+			}
+			h.logger.Error("Error deleting media:", err)
+			http.Error(w, "Failed to delete media", http.StatusInternalServerError)
+			return
+		}
+
+		// Return 200 OK - HTMX will handle removing the element from the DOM
+		w.WriteHeader(http.StatusOK)
+	}
+}
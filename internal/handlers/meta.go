@@ -0,0 +1,96 @@
+// internal/handlers/meta.go
+package handlers
+
+import (
+	"blog-portfolio/internal/config"
+	"blog-portfolio/internal/middleware"
+	"blog-portfolio/internal/models"
+	"blog-portfolio/web/layouts"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// pageData fills in the metadata shared by every public page. canonical is the
+// page's root-relative URL without the base URL.
+func pageData(cfg *config.Config, r *http.Request, title, description, canonical string) layouts.PageData {
+	baseURL := strings.TrimSuffix(cfg.App.BaseURL, "/")
+
+	data := layouts.PageData{
+		Title:       title,
+		Description: description,
+		IsAdmin:     middleware.IsAdmin(r),
+		SiteName:    cfg.App.Title,
+		SiteURL:     baseURL,
+		Canonical:   baseURL + canonical,
+		Type:        "website",
+		TwitterSite: cfg.SEO.TwitterHandle,
+	}
+	if cfg.SEO.DefaultImage != "" {
+		data.Image = absoluteURL(cfg.SEO.DefaultImage, baseURL)
+	}
+
+	return data
+}
+
+// postPageData fills in the article metadata for a post page
+func postPageData(cfg *config.Config, r *http.Request, post *models.Post) layouts.PageData {
+	data := pageData(cfg, r, post.Title+" | Blog", post.Description, "/blog/"+url.PathEscape(post.Slug))
+	data.Type = "article"
+	data.Headline = post.Title
+	data.Author = cfg.App.Author
+	if post.Author != nil {
+		data.Author = post.Author.Name()
+	}
+	switch {
+	case post.CoverImage != "":
+		data.Image = absoluteURL(post.CoverImage, data.SiteURL)
+	case cfg.OGImage.Enabled:
+		data.Image = data.SiteURL + ogImagePath(post)
+	}
+
+	modified := post.UpdatedAt
+	data.ModifiedTime = &modified
+	if post.PublishedAt != nil {
+		published := *post.PublishedAt
+		data.PublishedTime = &published
+	}
+
+	for _, tag := range post.Tags {
+		data.Tags = append(data.Tags, tag.Name)
+	}
+
+	return data
+}
+
+// authorPageData fills in the metadata for an author's page, using their avatar as
+// the share image when they have one
+func authorPageData(cfg *config.Config, r *http.Request, author *models.Author) layouts.PageData {
+	description := author.Bio
+	if description == "" {
+		description = "Posts by " + author.Name()
+	}
+	data := pageData(cfg, r, author.Name()+" | Blog", description, "/authors/"+url.PathEscape(author.Username))
+	data.Type = "profile"
+	if author.Avatar != "" {
+		data.Image = absoluteURL(author.Avatar, data.SiteURL)
+	}
+	return data
+}
+
+// blogCanonical returns the canonical URL of a blog listing page. The tag filter
+// and page number select different content, so they are kept.
+func blogCanonical(tag string, page int) string {
+	query := url.Values{}
+	if tag != "" {
+		query.Set("tag", tag)
+	}
+	if page > 1 {
+		query.Set("page", strconv.Itoa(page))
+	}
+	if len(query) == 0 {
+		return "/blog"
+	}
+	return "/blog?" + query.Encode()
+}
@@ -0,0 +1,82 @@
+// internal/handlers/og_handler.go
+package handlers
+
+import (
+	"blog-portfolio/internal/models"
+	"blog-portfolio/internal/ogimage"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// OGImage serves a published post's title card, drawing it the first time it is
+// requested after the post changes
+func (h *SEOHandlers) OGImage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.images == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		post, err := h.posts.GetPost(r.Context(), chi.URLParam(r, "slug"))
+		if err != nil {
+			h.logger.Error("Error fetching post:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if post == nil || !post.Published {
+			http.NotFound(w, r)
+			return
+		}
+
+		card := ogCard(post, h.config.App.Title)
+		name, err := h.images.File(post.Slug, card)
+		if err != nil {
+			h.logger.Error("Error rendering share image:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		file, err := os.Open(name)
+		if err != nil {
+			h.logger.Error("Error opening share image:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		defer file.Close()
+		info, err := file.Stat()
+		if err != nil {
+			h.logger.Error("Error opening share image:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		w.Header().Set("ETag", `"`+h.images.Key(card)+`"`)
+		http.ServeContent(w, r, "", info.ModTime(), file)
+	}
+}
+
+// ogCard describes the title card drawn for a post
+func ogCard(post *models.Post, siteName string) ogimage.Card {
+	card := ogimage.Card{
+		Title:    post.Title,
+		Date:     post.CreatedAt,
+		SiteName: siteName,
+	}
+	if post.PublishedAt != nil {
+		card.Date = *post.PublishedAt
+	}
+	for _, tag := range post.Tags {
+		card.Tags = append(card.Tags, tag.Name)
+	}
+	return card
+}
+
+// ogImagePath returns the root-relative URL of a post's title card
+func ogImagePath(post *models.Post) string {
+	return "/og/" + url.PathEscape(post.Slug) + ".png"
+}
@@ -2,26 +2,39 @@
 package handlers
 
 import (
+	"blog-portfolio/internal/config"
 	"blog-portfolio/internal/logger"
 	"blog-portfolio/internal/models"
 	"blog-portfolio/internal/service"
 	"blog-portfolio/web/pages"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 )
 
+// BlogPageSize is the number of posts on each page of the blog listing
+const BlogPageSize = 10
+
 type PostHandlers struct {
-	service *service.PostService
-	logger  *logger.Logger
+	service  *service.PostService
+	previews *service.PreviewService
+	users    *service.UserService
+	logger   *logger.Logger
+	config   *config.Config
 }
 
-func NewPostHandlers(service *service.PostService, logger *logger.Logger) *PostHandlers {
+func NewPostHandlers(service *service.PostService, previews *service.PreviewService, users *service.UserService, logger *logger.Logger, cfg *config.Config) *PostHandlers {
 	return &PostHandlers{
-		service: service,
-		logger:  logger,
+		service:  service,
+		previews: previews,
+		users:    users,
+		logger:   logger,
+		config:   cfg,
 	}
 }
 
@@ -40,7 +53,7 @@ func (h *PostHandlers) ListPosts() http.HandlerFunc {
 		}
 
 		// Set up pagination
-		limit := 10
+		limit := BlogPageSize
 		offset := (page - 1) * limit
 
 		// Important: Set published filter to true for public blog page
@@ -74,7 +87,9 @@ func (h *PostHandlers) ListPosts() http.HandlerFunc {
 			}
 			return
 		default:
-			err = pages.Blog(posts, page, tag).Render(ctx, w)
+			data := pageData(h.config, r, "Amogh's Eden", "Read my latest blog posts about anything that tickels my intellectual fancy", blogCanonical(tag, page))
+			data.FeedTag = tag
+			err = pages.Blog(data, posts, page, tag).Render(ctx, w)
 		}
 
 		if err != nil {
@@ -97,11 +112,48 @@ func (h *PostHandlers) GetPost() http.HandlerFunc {
 			return
 		}
 
+		// Old slugs permanently redirect to the post's current URL
 		if post == nil {
+			current, err := h.service.GetRedirectSlug(ctx, slug)
+			if err != nil {
+				h.logger.Error("Error looking up slug history:", err)
+				http.Error(w, "Failed to fetch post", http.StatusInternalServerError)
+				return
+			}
+			if current != "" {
+				target := "/blog/" + current
+				if r.URL.RawQuery != "" {
+					target += "?" + r.URL.RawQuery
+				}
+				http.Redirect(w, r, target, http.StatusMovedPermanently)
+				return
+			}
+		}
+
+		// Drafts and scheduled posts stay hidden until they are published,
+		// unless the request carries a valid preview token for the post
+		var preview *models.PreviewLink
+		if post != nil && !post.Published {
+			if token := r.URL.Query().Get("preview"); token != "" {
+				preview, err = h.previews.Verify(ctx, post, token)
+				if err != nil && !errors.Is(err, service.ErrInvalidPreviewToken) {
+					h.logger.Error("Error verifying preview token:", err)
+					http.Error(w, "Failed to fetch post", http.StatusInternalServerError)
+					return
+				}
+			}
+		}
+		if post == nil || (!post.Published && preview == nil) {
 			http.NotFound(w, r)
 			return
 		}
 
+		// Previews must never be cached or indexed
+		if preview != nil {
+			w.Header().Set("Cache-Control", "private, no-store")
+			w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+		}
+
 		// Handle different response types
 		switch {
 		case r.Header.Get("Accept") == "application/json":
@@ -111,7 +163,9 @@ func (h *PostHandlers) GetPost() http.HandlerFunc {
 				http.Error(w, "Error encoding response", http.StatusInternalServerError)
 			}
 		default:
-			err = pages.BlogPost(post).Render(ctx, w)
+			data := postPageData(h.config, r, post)
+			data.NoIndex = preview != nil
+			err = pages.BlogPost(data, post, preview).Render(ctx, w)
 			if err != nil {
 				h.logger.Error("Error rendering post page:", err)
 				http.Error(w, "Error rendering page", http.StatusInternalServerError)
@@ -120,6 +174,139 @@ func (h *PostHandlers) GetPost() http.HandlerFunc {
 	}
 }
 
+// GetAuthor handles an author's public page with their bio and published posts.
+// Accounts that haven't published anything have no page.
+func (h *PostHandlers) GetAuthor() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		author, err := h.users.GetAuthor(ctx, chi.URLParam(r, "username"))
+		if err != nil {
+			h.logger.Error("Error fetching author:", err)
+			http.Error(w, "Failed to fetch author", http.StatusInternalServerError)
+			return
+		}
+		if author == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		published := true
+		posts, err := h.service.ListPosts(ctx, models.PostFilter{
+			Published: &published,
+			AuthorID:  author.ID,
+		})
+		if err != nil {
+			h.logger.Error("Error listing posts:", err)
+			http.Error(w, "Failed to fetch posts", http.StatusInternalServerError)
+			return
+		}
+		if len(posts) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+
+		// Handle different response types
+		switch {
+		case r.Header.Get("Accept") == "application/json":
+			w.Header().Set("Content-Type", "application/json")
+			response := struct {
+				Author *models.Author `json:"author"`
+				Posts  []*models.Post `json:"posts"`
+			}{author, posts}
+			if err := json.NewEncoder(w).Encode(response); err != nil {
+				h.logger.Error("Error encoding author:", err)
+				http.Error(w, "Error encoding response", http.StatusInternalServerError)
+			}
+		default:
+			data := authorPageData(h.config, r, author)
+			if err := pages.Author(data, author, posts).Render(ctx, w); err != nil {
+				h.logger.Error("Error rendering author page:", err)
+				http.Error(w, "Error rendering page", http.StatusInternalServerError)
+			}
+		}
+	}
+}
+
+// searchPageSize is the number of results returned per search page
+const searchPageSize = 20
+
+// Search handles the search page and JSON search requests
+func (h *PostHandlers) Search() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+
+		results, err := h.search(r)
+		if err != nil {
+			h.logger.Error("Error searching posts:", err)
+			http.Error(w, "Failed to search posts", http.StatusInternalServerError)
+			return
+		}
+
+		// Handle different response types
+		switch {
+		case r.Header.Get("HX-Request") == "true":
+			err = pages.SearchResults(query, results).Render(ctx, w)
+		case r.Header.Get("Accept") == "application/json":
+			w.Header().Set("Content-Type", "application/json")
+			if results == nil {
+				results = []models.SearchResult{}
+			}
+			if err := json.NewEncoder(w).Encode(results); err != nil {
+				h.logger.Error("Error encoding search results:", err)
+				http.Error(w, "Error encoding response", http.StatusInternalServerError)
+			}
+			return
+		default:
+			canonical := "/search"
+			if query != "" {
+				canonical += "?q=" + url.QueryEscape(query)
+			}
+			data := pageData(h.config, r, "Search | Amogh's Eden", "Search all blog posts", canonical)
+			err = pages.Search(data, query, results).Render(ctx, w)
+		}
+
+		if err != nil {
+			h.logger.Error("Error rendering search page:", err)
+			http.Error(w, "Error rendering page", http.StatusInternalServerError)
+		}
+	}
+}
+
+// SearchResults returns the search-as-you-type results fragment for HTMX
+func (h *PostHandlers) SearchResults() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+
+		results, err := h.search(r)
+		if err != nil {
+			h.logger.Error("Error searching posts:", err)
+			http.Error(w, "Failed to search posts", http.StatusInternalServerError)
+			return
+		}
+
+		// Keep the address bar shareable while the reader types
+		w.Header().Set("HX-Replace-Url", "/search?q="+url.QueryEscape(query))
+
+		if err := pages.SearchResults(query, results).Render(r.Context(), w); err != nil {
+			h.logger.Error("Error rendering search results:", err)
+			http.Error(w, "Error rendering page", http.StatusInternalServerError)
+		}
+	}
+}
+
+// search runs the query from the request's q and page parameters
+func (h *PostHandlers) search(r *http.Request) ([]models.SearchResult, error) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	return h.service.Search(r.Context(), query, searchPageSize, (page-1)*searchPageSize)
+}
+
 // CreatePost handles blog post creation
 func (h *PostHandlers) CreatePost() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -135,8 +322,9 @@ func (h *PostHandlers) CreatePost() http.HandlerFunc {
 			return
 		}
 
+		creditAuthor(r, &post)
 		ctx := r.Context()
-		if err := h.service.CreatePost(ctx, &post, []int64{}); err != nil {
+		if err := h.service.CreatePost(ctx, &post, []int64{}, revisionInfo(r)); err != nil {
 			h.logger.Error("Error creating post:", err)
 			http.Error(w, "Failed to create post", http.StatusInternalServerError)
 			return
@@ -173,7 +361,7 @@ func (h *PostHandlers) UpdatePost() http.HandlerFunc {
 		post.ID = id
 
 		ctx := r.Context()
-		if err := h.service.UpdatePost(ctx, &post, []int64{}); err != nil {
+		if err := h.service.UpdatePost(ctx, &post, []int64{}, revisionInfo(r)); err != nil {
 			h.logger.Error("Error updating post:", err)
 			http.Error(w, "Failed to update post", http.StatusInternalServerError)
 			return
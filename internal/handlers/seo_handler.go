@@ -0,0 +1,181 @@
+// internal/handlers/seo_handler.go
+package handlers
+
+import (
+	"blog-portfolio/internal/config"
+	"blog-portfolio/internal/logger"
+	"blog-portfolio/internal/models"
+	"blog-portfolio/internal/ogimage"
+	"blog-portfolio/internal/service"
+	"blog-portfolio/internal/sitemap"
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SEOHandlers serves robots.txt, the sitemaps search engines crawl from and the
+// share images for posts
+type SEOHandlers struct {
+	logger *logger.Logger
+	config *config.Config
+	posts  *service.PostService
+	tags   *service.TagService
+	images *ogimage.Renderer // nil when share images are disabled
+}
+
+func NewSEOHandlers(logger *logger.Logger, cfg *config.Config, postService *service.PostService, tagService *service.TagService, images *ogimage.Renderer) *SEOHandlers {
+	return &SEOHandlers{
+		logger: logger,
+		config: cfg,
+		posts:  postService,
+		tags:   tagService,
+		images: images,
+	}
+}
+
+// Robots serves robots.txt. Outside production it disallows everything unless
+// indexing has been enabled for non-production sites.
+func (h *SEOHandlers) Robots() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var b strings.Builder
+		b.WriteString("User-agent: *\n")
+
+		if !h.config.Server.IsProduction() && !h.config.SEO.IndexNonProduction {
+			b.WriteString("Disallow: /\n")
+		} else {
+			for _, path := range h.config.SEO.RobotsDisallow {
+				fmt.Fprintf(&b, "Disallow: %s\n", path)
+			}
+			if len(h.config.SEO.RobotsDisallow) == 0 {
+				// An empty Disallow allows everything
+				b.WriteString("Disallow:\n")
+			}
+			fmt.Fprintf(&b, "\nSitemap: %s/sitemap.xml\n", h.baseURL())
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		w.Write([]byte(b.String()))
+	}
+}
+
+// Sitemap serves /sitemap.xml. Once there are more URLs than fit in one sitemap it
+// becomes a sitemap index pointing at /sitemap-1.xml, /sitemap-2.xml and so on.
+func (h *SEOHandlers) Sitemap() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		urls, err := h.sitemapURLs(r)
+		if err != nil {
+			h.logger.Error("Error building sitemap:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		chunks := sitemap.Split(urls, h.config.SEO.SitemapMaxURLs)
+		if len(chunks) == 1 {
+			h.writeXML(w, func(b *bytes.Buffer) error { return sitemap.WriteURLSet(b, urls) })
+			return
+		}
+
+		sitemaps := make([]sitemap.Sitemap, len(chunks))
+		for i, chunk := range chunks {
+			sitemaps[i] = sitemap.Sitemap{
+				Loc:     fmt.Sprintf("%s/sitemap-%d.xml", h.baseURL(), i+1),
+				LastMod: sitemap.LatestMod(chunk),
+			}
+		}
+		h.writeXML(w, func(b *bytes.Buffer) error { return sitemap.WriteIndex(b, sitemaps) })
+	}
+}
+
+// SitemapPage serves one of the sitemaps listed in the sitemap index
+func (h *SEOHandlers) SitemapPage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, err := strconv.Atoi(chi.URLParam(r, "page"))
+		if err != nil || page < 1 {
+			http.NotFound(w, r)
+			return
+		}
+
+		urls, err := h.sitemapURLs(r)
+		if err != nil {
+			h.logger.Error("Error building sitemap:", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		// A single sitemap is served as /sitemap.xml only
+		chunks := sitemap.Split(urls, h.config.SEO.SitemapMaxURLs)
+		if len(chunks) == 1 || page > len(chunks) {
+			http.NotFound(w, r)
+			return
+		}
+		h.writeXML(w, func(b *bytes.Buffer) error { return sitemap.WriteURLSet(b, chunks[page-1]) })
+	}
+}
+
+// sitemapURLs lists the home page, blog index, every published post, every tag page
+// and the page of everyone with a published post
+func (h *SEOHandlers) sitemapURLs(r *http.Request) ([]sitemap.URL, error) {
+	ctx := r.Context()
+	base := h.baseURL()
+
+	published := true
+	posts, err := h.posts.ListPosts(ctx, models.PostFilter{Published: &published})
+	if err != nil {
+		return nil, err
+	}
+	tags, err := h.tags.ListPublishedTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]sitemap.URL, 0, len(posts)+len(tags)+2)
+	urls = append(urls, sitemap.URL{Loc: base + "/"}, sitemap.URL{Loc: base + "/blog"})
+	for _, post := range posts {
+		updated := post.UpdatedAt
+		urls = append(urls, sitemap.URL{
+			Loc:     base + "/blog/" + url.PathEscape(post.Slug),
+			LastMod: &updated,
+		})
+	}
+	for _, tag := range tags {
+		urls = append(urls, sitemap.URL{Loc: base + "/blog?tag=" + url.QueryEscape(tag.Slug)})
+	}
+	authors := map[string]bool{}
+	for _, post := range posts {
+		if post.Author != nil && !authors[post.Author.Username] {
+			authors[post.Author.Username] = true
+			urls = append(urls, sitemap.URL{Loc: base + "/authors/" + url.PathEscape(post.Author.Username)})
+		}
+	}
+
+	// The home page and blog index change whenever a post does
+	if mod := sitemap.LatestMod(urls); mod != nil {
+		urls[0].LastMod = mod
+		urls[1].LastMod = mod
+	}
+
+	return urls, nil
+}
+
+func (h *SEOHandlers) writeXML(w http.ResponseWriter, write func(*bytes.Buffer) error) {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		h.logger.Error("Error encoding sitemap:", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write(buf.Bytes())
+}
+
+func (h *SEOHandlers) baseURL() string {
+	return strings.TrimSuffix(h.config.App.BaseURL, "/")
+}
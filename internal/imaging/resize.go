@@ -0,0 +1,22 @@
+// internal/imaging/resize.go
+package imaging
+
+import (
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// ResizeToWidth scales img down to width, keeping its aspect ratio. Images that are already
+// narrow enough are returned unchanged, since upscaling only adds bytes.
+func ResizeToWidth(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	if bounds.Dx() <= width {
+		return img
+	}
+
+	height := max(1, bounds.Dy()*width/bounds.Dx())
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
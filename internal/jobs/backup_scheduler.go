@@ -0,0 +1,81 @@
+// internal/jobs/backup_scheduler.go
+package jobs
+
+import (
+	"blog-portfolio/internal/backup"
+	"blog-portfolio/internal/logger"
+	"context"
+	"time"
+)
+
+// backupRetryDelay is how long to wait before trying again after a failed backup
+const backupRetryDelay = 10 * time.Minute
+
+// BackupScheduler snapshots the database at a fixed interval while the server runs
+type BackupScheduler struct {
+	logger   *logger.Logger
+	backups  *backup.Manager
+	interval time.Duration
+}
+
+func NewBackupScheduler(logger *logger.Logger, backups *backup.Manager, interval time.Duration) *BackupScheduler {
+	return &BackupScheduler{
+		logger:   logger,
+		backups:  backups,
+		interval: interval,
+	}
+}
+
+// Run takes a snapshot whenever the newest one is older than the interval, until ctx
+// is cancelled, so restarting the server doesn't take an extra one. It returns
+// immediately when the interval is zero.
+func (s *BackupScheduler) Run(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+
+	wait := s.nextWait()
+	for {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if s.backup() {
+			wait = s.nextWait()
+		} else {
+			wait = min(backupRetryDelay, s.interval)
+		}
+	}
+}
+
+func (s *BackupScheduler) backup() bool {
+	snapshot, err := s.backups.Create()
+	if err != nil {
+		s.logger.Error("Error backing up database:", err)
+		return false
+	}
+	s.logger.Info("Backed up database to", snapshot.Path)
+	return true
+}
+
+// nextWait returns how long until the newest snapshot is an interval old
+func (s *BackupScheduler) nextWait() time.Duration {
+	latest, err := s.backups.Latest()
+	if err != nil {
+		s.logger.Error("Error listing backups:", err)
+		return s.interval
+	}
+	if latest == nil {
+		return 0
+	}
+
+	wait := time.Until(latest.CreatedAt.Add(s.interval))
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
@@ -0,0 +1,76 @@
+// internal/jobs/publisher.go
+package jobs
+
+import (
+	"blog-portfolio/internal/logger"
+	"blog-portfolio/internal/service"
+	"context"
+	"time"
+)
+
+// maxPublisherSleep bounds how long the publisher waits between checks, so schedules
+// written by another process or with a skewed clock are still picked up
+const maxPublisherSleep = time.Minute
+
+// Publisher flips scheduled posts live once their publish time arrives
+type Publisher struct {
+	logger *logger.Logger
+	posts  *service.PostService
+}
+
+func NewPublisher(logger *logger.Logger, posts *service.PostService) *Publisher {
+	return &Publisher{
+		logger: logger,
+		posts:  posts,
+	}
+}
+
+// Run publishes due posts until ctx is cancelled. Schedules missed while the
+// server was down are published immediately on start.
+func (p *Publisher) Run(ctx context.Context) {
+	for {
+		p.publishDue(ctx)
+
+		timer := time.NewTimer(p.nextWait(ctx))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-p.posts.ScheduleChanged():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+func (p *Publisher) publishDue(ctx context.Context) {
+	count, err := p.posts.PublishDue(ctx)
+	if err != nil {
+		p.logger.Error("Error publishing scheduled posts:", err)
+		return
+	}
+	if count > 0 {
+		p.logger.Info("Published scheduled posts:", count)
+	}
+}
+
+// nextWait returns how long to sleep until the next scheduled post is due
+func (p *Publisher) nextWait(ctx context.Context) time.Duration {
+	next, err := p.posts.NextScheduledAt(ctx)
+	if err != nil {
+		p.logger.Error("Error fetching next scheduled post:", err)
+		return maxPublisherSleep
+	}
+	if next == nil {
+		return maxPublisherSleep
+	}
+
+	wait := time.Until(*next)
+	if wait < 0 {
+		return 0
+	}
+	if wait > maxPublisherSleep {
+		return maxPublisherSleep
+	}
+	return wait
+}
@@ -0,0 +1,59 @@
+// internal/jobs/trash_purger.go
+package jobs
+
+import (
+	"blog-portfolio/internal/logger"
+	"blog-portfolio/internal/service"
+	"context"
+	"time"
+)
+
+// trashPurgeInterval is how often expired posts are purged from the trash
+const trashPurgeInterval = time.Hour
+
+// TrashPurger permanently deletes posts once they have been in the trash longer than the retention period
+type TrashPurger struct {
+	logger    *logger.Logger
+	posts     *service.PostService
+	retention time.Duration
+}
+
+func NewTrashPurger(logger *logger.Logger, posts *service.PostService, retention time.Duration) *TrashPurger {
+	return &TrashPurger{
+		logger:    logger,
+		posts:     posts,
+		retention: retention,
+	}
+}
+
+// Run purges expired posts on start and then periodically until ctx is cancelled.
+// It returns immediately when retention is zero, leaving purging to the admin.
+func (p *TrashPurger) Run(ctx context.Context) {
+	if p.retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		p.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *TrashPurger) purge(ctx context.Context) {
+	count, err := p.posts.PurgeExpiredTrash(ctx, p.retention)
+	if err != nil {
+		p.logger.Error("Error purging trash:", err)
+		return
+	}
+	if count > 0 {
+		p.logger.Info("Purged posts from trash:", count)
+	}
+}
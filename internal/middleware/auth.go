@@ -2,9 +2,9 @@
 package middleware
 
 import (
+	"blog-portfolio/internal/config"
 	"context"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
@@ -35,50 +35,64 @@ func IsAdmin(r *http.Request) bool {
 	return false
 }
 
-// Modify the RequireAuth middleware
-func RequireAuth(next http.Handler) http.Handler {
+// UserLoader looks up the current state of a signed in account, returning nil once it
+// has been deleted
+type UserLoader func(ctx context.Context, id int64) (*User, error)
+
+// Sessions issues and checks the signed session cookies that keep admins logged in
+type Sessions struct {
+	cfg      config.AuthConfig
+	loadUser UserLoader
+}
+
+// NewSessions returns sessions signed with cfg.Secret. Each request reloads the
+// account with loadUser, so role changes and deleted accounts take effect at once.
+func NewSessions(cfg config.AuthConfig, loadUser UserLoader) *Sessions {
+	return &Sessions{cfg: cfg, loadUser: loadUser}
+}
+
+// RequireAuth redirects to the login page unless the request has a valid session
+func (s *Sessions) RequireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Check for session cookie
-		cookie, err := r.Cookie("session")
+		cookie, err := r.Cookie(s.cfg.CookieName)
 		if err != nil {
 			http.Redirect(w, r, "/login", http.StatusSeeOther)
 			return
 		}
 
 		// Validate JWT token
-		token, err := validateToken(cookie.Value)
+		token, err := s.validateToken(cookie.Value)
 		if err != nil {
-			http.SetCookie(w, &http.Cookie{
-				Name:     "session",
-				Value:    "",
-				Path:     "/",
-				Expires:  time.Now().Add(-1 * time.Hour),
-				HttpOnly: true,
-				Secure:   true,
-				SameSite: http.SameSiteStrictMode,
-			})
+			s.ClearCookie(w)
 			http.Redirect(w, r, "/login", http.StatusSeeOther)
 			return
 		}
 
-		// Extract claims
+		// Extract claims. Tokens issued for another purpose, like the second login
+		// step, don't sign anyone in.
 		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || !token.Valid {
+		if _, scoped := claims["purpose"]; !ok || !token.Valid || scoped {
 			http.Redirect(w, r, "/login", http.StatusSeeOther)
 			return
 		}
 
-		// Check if user has admin role
-		role, _ := claims["role"].(string)
-		isAdmin := role == "admin"
-
-		// Create user context
-		user := &User{
-			ID:       int64(claims["user_id"].(float64)),
-			Username: claims["username"].(string),
-			Role:     role,
+		// Load the account as it is now rather than as it was when the token was issued
+		userID, _ := claims["user_id"].(float64)
+		user, err := s.loadUser(r.Context(), int64(userID))
+		if err != nil {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if user == nil {
+			s.ClearCookie(w)
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
 		}
 
+		// Check if user has admin role
+		isAdmin := user.Role == "admin"
+
 		// Add user and admin status to context
 		ctx := context.WithValue(r.Context(), UserContextKey, user)
 		ctx = context.WithValue(ctx, IsAdminContextKey, isAdmin)
@@ -89,7 +103,7 @@ func RequireAuth(next http.Handler) http.Handler {
 }
 
 // validateToken validates the JWT token
-func validateToken(tokenString string) (*jwt.Token, error) {
+func (s *Sessions) validateToken(tokenString string) (*jwt.Token, error) {
 	// Remove "Bearer " prefix if present
 	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
 
@@ -99,38 +113,119 @@ func validateToken(tokenString string) (*jwt.Token, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, jwt.ErrSignatureInvalid
 		}
-
-		// Get secret key from environment
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			secret = "your-secret-key" // Default for development
-		}
-
-		return []byte(secret), nil
+		return []byte(s.cfg.Secret), nil
 	})
 }
 
 // CreateToken creates a new JWT token for a user
-func CreateToken(userID int64, username, role string) (string, error) {
-	// Get secret key from environment
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "your-secret-key" // Default for development
-	}
-
+func (s *Sessions) CreateToken(userID int64, username, role string) (string, error) {
 	// Create claims
 	claims := jwt.MapClaims{
 		"user_id":  userID,
 		"username": username,
 		"role":     role,
-		"exp":      time.Now().Add(24 * time.Hour).Unix(),
+		"exp":      time.Now().Add(s.cfg.SessionTTL()).Unix(),
 	}
 
 	// Create token
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	// Sign and return token
-	return token.SignedString([]byte(secret))
+	return token.SignedString([]byte(s.cfg.Secret))
+}
+
+// SetCookie stores the session token in the session cookie
+func (s *Sessions) SetCookie(w http.ResponseWriter, token string) {
+	http.SetCookie(w, s.cookie(token, time.Now().Add(s.cfg.SessionTTL())))
+}
+
+// ClearCookie deletes the session cookie
+func (s *Sessions) ClearCookie(w http.ResponseWriter) {
+	http.SetCookie(w, s.cookie("", time.Now().Add(-1*time.Hour)))
+}
+
+func (s *Sessions) cookie(value string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     s.cfg.CookieName,
+		Value:    value,
+		Path:     "/",
+		Domain:   s.cfg.CookieDomain,
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   s.cfg.CookieSecure,
+		SameSite: s.cfg.SameSite(),
+	}
+}
+
+// secondFactorPurpose marks the token of a login whose password was right but whose
+// second factor hasn't been checked yet
+const secondFactorPurpose = "second_factor"
+
+// secondFactorTTL is how long the second login step may take
+const secondFactorTTL = 5 * time.Minute
+
+// StartSecondFactor remembers, in a short-lived cookie of its own, that userID gave the
+// right password and still has to enter a code from their authenticator app
+func (s *Sessions) StartSecondFactor(w http.ResponseWriter, userID int64) error {
+	expires := time.Now().Add(secondFactorTTL)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID,
+		"purpose": secondFactorPurpose,
+		"exp":     expires.Unix(),
+	})
+	signed, err := token.SignedString([]byte(s.cfg.Secret))
+	if err != nil {
+		return err
+	}
+
+	cookie := s.cookie(signed, expires)
+	cookie.Name = s.secondFactorCookieName()
+	http.SetCookie(w, cookie)
+	return nil
+}
+
+// SecondFactorUser returns the ID of the user whose second login step is pending
+func (s *Sessions) SecondFactorUser(r *http.Request) (int64, bool) {
+	cookie, err := r.Cookie(s.secondFactorCookieName())
+	if err != nil {
+		return 0, false
+	}
+	token, err := s.validateToken(cookie.Value)
+	if err != nil || !token.Valid {
+		return 0, false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != secondFactorPurpose {
+		return 0, false
+	}
+	userID, ok := claims["user_id"].(float64)
+	return int64(userID), ok
+}
+
+// ClearSecondFactor deletes the pending second login step cookie
+func (s *Sessions) ClearSecondFactor(w http.ResponseWriter) {
+	cookie := s.cookie("", time.Now().Add(-1*time.Hour))
+	cookie.Name = s.secondFactorCookieName()
+	http.SetCookie(w, cookie)
+}
+
+func (s *Sessions) secondFactorCookieName() string {
+	return s.cfg.CookieName + "_2fa"
+}
+
+// RequireRole answers 403 Forbidden unless the signed in user's role passes allowed.
+// Use it inside RequireAuth.
+func RequireRole(allowed func(role string) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := GetUserFromContext(r.Context())
+			if user == nil || !allowed(user.Role) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
 // GetUserFromContext retrieves the user from the context
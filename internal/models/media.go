@@ -0,0 +1,69 @@
+// internal/models/media.go
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Media variant names
+const (
+	MediaThumbnail = "thumbnail"  // Grid previews in the media library
+	MediaContent   = "content"    // Images inside post content
+	MediaContent2x = "content_2x" // High density screens
+)
+
+// Media is an uploaded image and the resized variants generated from it
+type Media struct {
+	ID          int64          `json:"id"`
+	Filename    string         `json:"filename"` // Name of the uploaded file
+	Path        string         `json:"path"`     // Storage path of the original
+	ContentType string         `json:"content_type"`
+	Width       int            `json:"width"`
+	Height      int            `json:"height"`
+	Size        int64          `json:"size"`
+	AltText     string         `json:"alt_text"`
+	Variants    []MediaVariant `json:"variants"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// MediaVariant is a resized copy of an image in one format
+type MediaVariant struct {
+	Name   string `json:"name"`
+	Format string `json:"format"` // "jpeg", "png" or "webp"
+	Path   string `json:"path"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Size   int64  `json:"size"`
+}
+
+// URL returns the public URL of the original upload
+func (m *Media) URL() string {
+	return MediaURL(m.Path)
+}
+
+// VariantURL returns the URL of the smallest file generated for the named variant,
+// falling back to the original when there is none
+func (m *Media) VariantURL(name string) string {
+	var best *MediaVariant
+	for i, v := range m.Variants {
+		if v.Name == name && (best == nil || v.Size < best.Size) {
+			best = &m.Variants[i]
+		}
+	}
+	if best == nil {
+		return m.URL()
+	}
+	return MediaURL(best.Path)
+}
+
+// Markdown returns the Markdown that embeds the image in a post
+func (m *Media) Markdown() string {
+	alt := strings.NewReplacer("[", "", "]", "").Replace(m.AltText)
+	return "![" + alt + "](" + m.VariantURL(MediaContent) + ")"
+}
+
+// MediaURL returns the public URL of a stored media path
+func MediaURL(path string) string {
+	return "/media/" + path
+}
@@ -2,11 +2,8 @@
 package models
 
 import (
+	"blog-portfolio/internal/utils"
 	"time"
-
-	"github.com/gomarkdown/markdown"
-	"github.com/gomarkdown/markdown/html"
-	"github.com/gomarkdown/markdown/parser"
 )
 
 type Post struct {
@@ -20,33 +17,56 @@ type Post struct {
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	PublishedAt *time.Time `json:"published_at,omitempty"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"` // Pending publish time, cleared once the post goes live
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`   // Set while the post is in the trash
 	Tags        []Tag      `json:"tags,omitempty"`
+	AuthorID    *int64     `json:"author_id,omitempty"` // Nil for posts whose author was deleted
+	Author      *Author    `json:"author,omitempty"`
 	ReadingTime int        `json:"reading_time"`
+	HideTOC     bool       `json:"hide_toc"`
+
+	// Rendered output, computed when the post is saved
+	ContentHTML string           `json:"content_html,omitempty"`
+	TOC         []utils.TOCEntry `json:"toc,omitempty"`
 }
 
 // PostFilter represents filters for querying posts
 type PostFilter struct {
 	Tag       string
 	Published *bool
+	Trashed   bool  // List only trashed posts instead of excluding them
+	AuthorID  int64 // List only this author's posts when set
+	WithTags  bool  // Also load each post's tags
 	Limit     int
 	Offset    int
 }
 
+// ParsedContent renders the post's Markdown content to HTML
 func (p *Post) ParsedContent() string {
-	// Create markdown parser with extensions
-	extensions := parser.CommonExtensions | parser.AutoHeadingIDs | parser.NoEmptyLineBeforeBlock
-	post := parser.NewWithExtensions(extensions)
-
-	// Parse the markdown
-	doc := post.Parse([]byte(p.Content))
-
-	// Create HTML renderer with options
-	opts := html.RendererOptions{
-		Flags: html.CommonFlags | html.HrefTargetBlank,
-		Title: p.Title,
-	}
-	renderer := html.NewRenderer(opts)
-
-	// Render to HTML
-	return string(markdown.Render(doc, renderer))
+	return utils.RenderMarkdown(p.Title, p.Content)
+}
+
+// Render computes the post's HTML, table of contents and reading time from its Markdown
+func (p *Post) Render(tocOpts utils.TOCOptions) {
+	rendered := utils.Render(p.Title, p.Content, tocOpts)
+	p.ContentHTML = rendered.HTML
+	p.TOC = rendered.TOC
+	p.ReadingTime = utils.CalculateReadingTime(p.Content)
+}
+
+// IsScheduled reports whether the post is waiting to be published automatically
+func (p *Post) IsScheduled() bool {
+	return !p.Published && p.ScheduledAt != nil
+}
+
+// ShowTOC reports whether the post page should display its table of contents
+func (p *Post) ShowTOC() bool {
+	return !p.HideTOC && len(p.TOC) > 0
+}
+
+// SearchResult is a post matched by a full-text search
+type SearchResult struct {
+	Post    *Post   `json:"post"`
+	Snippet string  `json:"snippet"` // HTML-escaped excerpt with matches wrapped in <mark>
+	Rank    float64 `json:"rank"`
 }
@@ -0,0 +1,13 @@
+// internal/models/preview.go
+package models
+
+import "time"
+
+// PreviewLink grants access to an unpublished post until it expires or is revoked
+type PreviewLink struct {
+	ID        string    `json:"id"`
+	PostID    int64     `json:"post_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	URL       string    `json:"url,omitempty"` // Signed share URL, built on demand and never stored
+}
@@ -0,0 +1,24 @@
+// internal/models/revision.go
+package models
+
+import "time"
+
+// PostRevision is a snapshot of a post's content taken every time it is saved
+type PostRevision struct {
+	ID          int64     `json:"id"`
+	PostID      int64     `json:"post_id"`
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	Content     string    `json:"content"`
+	Description string    `json:"description"`
+	CoverImage  string    `json:"cover_image"`
+	Author      string    `json:"author"`
+	Note        string    `json:"note"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RevisionInfo describes who made a change and why, recorded with the revision it creates
+type RevisionInfo struct {
+	Author string
+	Note   string
+}
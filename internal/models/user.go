@@ -0,0 +1,117 @@
+// internal/models/user.go
+package models
+
+import "time"
+
+// User is an account that can sign in to the admin. The display name, bio and avatar
+// are shown publicly on the author's posts and author page.
+type User struct {
+	ID           int64     `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"-"` // bcrypt hash, never sent to clients
+	Role         string    `json:"role"`
+	DisplayName  string    `json:"display_name"`
+	Bio          string    `json:"bio"`
+	Avatar       string    `json:"avatar"` // Image URL, empty for none
+	TOTPSecret   string    `json:"-"`      // Base32 TOTP secret, empty until two-factor login is set up
+	PostCount    int       `json:"post_count,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Name is how the user is credited on posts: their display name, or their username
+// until they set one
+func (u *User) Name() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	return u.Username
+}
+
+// Profile returns the user's public profile, the only part of the account shown to
+// readers
+func (u *User) Profile() *Author {
+	return &Author{
+		ID:          u.ID,
+		Username:    u.Username,
+		DisplayName: u.DisplayName,
+		Bio:         u.Bio,
+		Avatar:      u.Avatar,
+	}
+}
+
+// Author is the public profile of a user, credited on their posts and shown on their
+// author page
+type Author struct {
+	ID          int64  `json:"-"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio"`
+	Avatar      string `json:"avatar"` // Image URL, empty for none
+}
+
+// Name is how the author is credited: their display name, or their username until
+// they set one
+func (a *Author) Name() string {
+	if a.DisplayName != "" {
+		return a.DisplayName
+	}
+	return a.Username
+}
+
+// TwoFactorEnabled reports whether signing in also needs a code from an authenticator app
+func (u *User) TwoFactorEnabled() bool {
+	return u.TOTPSecret != ""
+}
+
+// Roles, from most to least trusted
+const (
+	RoleAdmin       = "admin"       // Everything, including accounts and backups
+	RoleEditor      = "editor"      // Writes, edits and publishes any post
+	RoleAuthor      = "author"      // Writes, edits and publishes their own posts
+	RoleContributor = "contributor" // Writes drafts of their own for an editor to publish
+)
+
+// Roles lists every role, from most to least trusted
+var Roles = []string{RoleAdmin, RoleEditor, RoleAuthor, RoleContributor}
+
+// ValidRole reports whether role is one of Roles
+func ValidRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// CanManageSite reports whether role may manage accounts, backups and site-wide tasks
+func CanManageSite(role string) bool {
+	return role == RoleAdmin
+}
+
+// CanEditAllPosts reports whether role may change, publish and purge anyone's posts
+func CanEditAllPosts(role string) bool {
+	return role == RoleAdmin || role == RoleEditor
+}
+
+// CanPublish reports whether role may publish or schedule posts
+func CanPublish(role string) bool {
+	return CanEditAllPosts(role) || role == RoleAuthor
+}
+
+// CanEditPost reports whether the user with the given ID and role may change post.
+// Authors may change their own posts, and contributors their own until they are
+// published or scheduled.
+func CanEditPost(userID int64, role string, post *Post) bool {
+	if CanEditAllPosts(role) {
+		return true
+	}
+	if post.AuthorID == nil || *post.AuthorID != userID {
+		return false
+	}
+	if role == RoleAuthor {
+		return true
+	}
+	return role == RoleContributor && !post.Published && post.ScheduledAt == nil
+}
@@ -0,0 +1,314 @@
+// internal/ogimage/ogimage.go
+package ogimage
+
+import (
+	"blog-portfolio/internal/config"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomedium"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// Size of the generated cards, the dimensions social networks recommend for
+// Open Graph images
+const (
+	Width  = 1200
+	Height = 630
+)
+
+// layoutVersion is part of every cache key. Bump it when the layout changes so
+// cached cards are drawn again.
+const layoutVersion = "1"
+
+const (
+	padding       = 80
+	maxTitleLines = 4
+	maxTitleSize  = 72
+	minTitleSize  = 44
+)
+
+// Fonts are compiled into the binary and picked by name in the configuration
+var fonts = map[string][]byte{
+	"go-regular":   goregular.TTF,
+	"go-medium":    gomedium.TTF,
+	"go-bold":      gobold.TTF,
+	"go-italic":    goitalic.TTF,
+	"go-mono":      gomono.TTF,
+	"go-mono-bold": gomonobold.TTF,
+}
+
+// Card is the content drawn on a title card
+type Card struct {
+	Title    string
+	Date     time.Time
+	Tags     []string
+	SiteName string
+}
+
+// Renderer draws title cards and caches them on disk by content hash, so a card is
+// only drawn again when its content or the style changes. Each post keeps only its
+// latest card, and the cache directory can be cleared at any time.
+type Renderer struct {
+	dir        string
+	background color.Color
+	foreground color.Color
+	accent     color.Color
+	muted      color.Color
+	titleFont  *truetype.Font
+	bodyFont   *truetype.Font
+
+	// style identifies the configured colors and fonts in cache keys
+	style string
+}
+
+// New creates a renderer from the configuration
+func New(cfg config.OGImageConfig) (*Renderer, error) {
+	r := &Renderer{
+		dir: cfg.CacheDir,
+		style: strings.Join([]string{
+			cfg.Background, cfg.Foreground, cfg.Accent, cfg.TitleFont, cfg.BodyFont,
+		}, "|"),
+	}
+
+	var err error
+	if r.background, err = parseColor(cfg.Background); err != nil {
+		return nil, fmt.Errorf("og_image.background: %w", err)
+	}
+	if r.foreground, err = parseColor(cfg.Foreground); err != nil {
+		return nil, fmt.Errorf("og_image.foreground: %w", err)
+	}
+	if r.accent, err = parseColor(cfg.Accent); err != nil {
+		return nil, fmt.Errorf("og_image.accent: %w", err)
+	}
+	if r.titleFont, err = loadFont(cfg.TitleFont); err != nil {
+		return nil, fmt.Errorf("og_image.title_font: %w", err)
+	}
+	if r.bodyFont, err = loadFont(cfg.BodyFont); err != nil {
+		return nil, fmt.Errorf("og_image.body_font: %w", err)
+	}
+
+	// Secondary text is the foreground faded towards the background
+	r.muted = blend(r.foreground, r.background, 0.6)
+
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return nil, err
+	}
+	// Cards used to be named by key alone, with nothing to prune them by
+	if err := r.prune("", ""); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Key returns the content hash identifying a card
+func (r *Renderer) Key(card Card) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%s\x00%s",
+		layoutVersion, r.style, card.Title, card.Date.Format("2006-01-02"), strings.Join(card.Tags, "\x01"), card.SiteName)
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// File returns the path of the card's PNG for the post with the given slug, drawing
+// it first if it isn't cached. Drawing a new card removes the post's previous ones.
+func (r *Renderer) File(slug string, card Card) (string, error) {
+	name := filepath.Join(r.dir, slug+"."+r.Key(card)+".png")
+	if _, err := os.Stat(name); err == nil {
+		return name, nil
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	// Write to a temporary file first so a concurrent request never serves half a card
+	tmp, err := os.CreateTemp(r.dir, ".card-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := r.Render(tmp, card); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return "", err
+	}
+
+	if err := r.prune(slug, filepath.Base(name)); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// prune removes the post's cached cards other than keep, or with an empty slug those
+// named by key alone. Card names are the slug and key joined by a dot, and the key
+// never contains one.
+func (r *Renderer) prune(slug, keep string) error {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		base, ok := strings.CutSuffix(name, ".png")
+		if !ok || name == keep {
+			continue
+		}
+		owner := ""
+		if i := strings.LastIndex(base, "."); i >= 0 {
+			owner = base[:i]
+		}
+		if owner != slug {
+			continue
+		}
+		if err := os.Remove(filepath.Join(r.dir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Render draws the card as a PNG
+func (r *Renderer) Render(w io.Writer, card Card) error {
+	dc := gg.NewContext(Width, Height)
+	dc.SetColor(r.background)
+	dc.Clear()
+
+	// Accent bar down the left edge
+	dc.SetColor(r.accent)
+	dc.DrawRectangle(0, 0, 16, Height)
+	dc.Fill()
+
+	textWidth := float64(Width - 2*padding)
+
+	// Site name along the top
+	if card.SiteName != "" {
+		dc.SetFontFace(r.face(r.bodyFont, 32))
+		dc.SetColor(r.accent)
+		dc.DrawStringAnchored(card.SiteName, padding, padding, 0, 1)
+	}
+
+	// Title, shrunk until it fits and vertically centred in the space left
+	size, lines := r.fitTitle(dc, card.Title, textWidth)
+	lineHeight := size * 1.2
+	top := (Height - lineHeight*float64(len(lines))) / 2
+	dc.SetFontFace(r.face(r.titleFont, size))
+	dc.SetColor(r.foreground)
+	for i, line := range lines {
+		dc.DrawStringAnchored(line, padding, top+lineHeight*float64(i), 0, 1)
+	}
+
+	// Date and tags along the bottom
+	var footer []string
+	if !card.Date.IsZero() {
+		footer = append(footer, card.Date.Format("January 2, 2006"))
+	}
+	if len(card.Tags) > 0 {
+		tags := make([]string, len(card.Tags))
+		for i, tag := range card.Tags {
+			tags[i] = "#" + tag
+		}
+		footer = append(footer, strings.Join(tags, "  "))
+	}
+	if len(footer) > 0 {
+		dc.SetFontFace(r.face(r.bodyFont, 28))
+		dc.SetColor(r.muted)
+		dc.DrawStringAnchored(truncate(dc, strings.Join(footer, "  ·  "), textWidth), padding, Height-padding, 0, 0)
+	}
+
+	return dc.EncodePNG(w)
+}
+
+// fitTitle picks the largest title size that wraps into maxTitleLines, truncating
+// the title at the smallest size if it still doesn't fit
+func (r *Renderer) fitTitle(dc *gg.Context, title string, width float64) (float64, []string) {
+	var lines []string
+	size := float64(maxTitleSize)
+	for ; size >= minTitleSize; size -= 4 {
+		dc.SetFontFace(r.face(r.titleFont, size))
+		lines = dc.WordWrap(title, width)
+		if len(lines) <= maxTitleLines {
+			return size, lines
+		}
+	}
+
+	size = minTitleSize
+	dc.SetFontFace(r.face(r.titleFont, size))
+	lines = dc.WordWrap(title, width)[:maxTitleLines]
+	lines[maxTitleLines-1] = truncate(dc, lines[maxTitleLines-1]+"…", width)
+	return size, lines
+}
+
+func (r *Renderer) face(f *truetype.Font, size float64) font.Face {
+	return truetype.NewFace(f, &truetype.Options{Size: size})
+}
+
+// truncate shortens s with an ellipsis until it fits in width with the current face
+func truncate(dc *gg.Context, s string, width float64) string {
+	if w, _ := dc.MeasureString(s); w <= width {
+		return s
+	}
+	runes := []rune(strings.TrimSuffix(s, "…"))
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := strings.TrimSpace(string(runes)) + "…"
+		if w, _ := dc.MeasureString(candidate); w <= width {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func loadFont(name string) (*truetype.Font, error) {
+	ttf, ok := fonts[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown font %q", name)
+	}
+	return truetype.Parse(ttf)
+}
+
+// parseColor parses a #rgb or #rrggbb color
+func parseColor(s string) (color.Color, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+
+	var r, g, b uint8
+	if len(hex) != 6 {
+		return nil, fmt.Errorf("invalid color %q", s)
+	}
+	if _, err := fmt.Sscanf(hex, "%02x%02x%02x", &r, &g, &b); err != nil {
+		return nil, fmt.Errorf("invalid color %q", s)
+	}
+
+	return color.RGBA{R: r, G: g, B: b, A: 0xff}, nil
+}
+
+// blend mixes a towards b by t, from 0 (all a) to 1 (all b)
+func blend(a, b color.Color, t float64) color.Color {
+	ar, ag, ab, _ := a.RGBA()
+	br, bg, bb, _ := b.RGBA()
+	mix := func(x, y uint32) uint8 {
+		return uint8((float64(x)*(1-t) + float64(y)*t) / 0x101)
+	}
+	return color.RGBA{R: mix(ar, br), G: mix(ag, bg), B: mix(ab, bb), A: 0xff}
+}
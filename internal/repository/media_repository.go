@@ -0,0 +1,183 @@
+// internal/repository/media_repository.go
+package repository
+
+import (
+	"blog-portfolio/internal/database"
+	"blog-portfolio/internal/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+type MediaRepository struct {
+	db *database.Database
+}
+
+func NewMediaRepository(db *database.Database) *MediaRepository {
+	return &MediaRepository{db: db}
+}
+
+const mediaColumns = `id, filename, path, content_type, width, height, size, alt_text, variants, created_at`
+
+// Create stores a new media item
+func (r *MediaRepository) Create(ctx context.Context, media *models.Media) error {
+	variants, err := json.Marshal(media.Variants)
+	if err != nil {
+		return err
+	}
+
+	return r.db.QueryRowContext(ctx, `
+        INSERT INTO media (filename, path, content_type, width, height, size, alt_text, variants)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+        RETURNING id, created_at`,
+		media.Filename,
+		media.Path,
+		media.ContentType,
+		media.Width,
+		media.Height,
+		media.Size,
+		media.AltText,
+		string(variants),
+	).Scan(&media.ID, &media.CreatedAt)
+}
+
+// Get retrieves a media item by ID, returning nil if it doesn't exist
+func (r *MediaRepository) Get(ctx context.Context, id int64) (*models.Media, error) {
+	media, err := scanMedia(r.db.QueryRowContext(ctx,
+		"SELECT "+mediaColumns+" FROM media WHERE id = ?",
+		id,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return media, nil
+}
+
+// List returns media items newest first, optionally filtered by a search on the file
+// name and alt text
+func (r *MediaRepository) List(ctx context.Context, query string, limit, offset int) ([]*models.Media, error) {
+	q := "SELECT " + mediaColumns + " FROM media"
+	var args []interface{}
+
+	if query = strings.TrimSpace(query); query != "" {
+		// LOWER on both sides keeps the search case-insensitive on every database
+		q += " WHERE LOWER(filename) LIKE LOWER(?) ESCAPE '\\' OR LOWER(alt_text) LIKE LOWER(?) ESCAPE '\\'"
+		pattern := "%" + escapeLike(query) + "%"
+		args = append(args, pattern, pattern)
+	}
+
+	q += " ORDER BY created_at DESC, id DESC"
+	if limit > 0 {
+		q += " LIMIT ? OFFSET ?"
+		args = append(args, limit, offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.Media
+	for rows.Next() {
+		media, err := scanMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, media)
+	}
+	return items, rows.Err()
+}
+
+// ListAll returns every media item, oldest first
+func (r *MediaRepository) ListAll(ctx context.Context) ([]*models.Media, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+mediaColumns+" FROM media ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.Media
+	for rows.Next() {
+		media, err := scanMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, media)
+	}
+	return items, rows.Err()
+}
+
+// UpdateAltText changes a media item's alt text
+func (r *MediaRepository) UpdateAltText(ctx context.Context, id int64, alt string) error {
+	result, err := r.db.ExecContext(ctx, "UPDATE media SET alt_text = ? WHERE id = ?", alt, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Delete removes a media item
+func (r *MediaRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM media WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMedia(row rowScanner) (*models.Media, error) {
+	media := &models.Media{}
+	var variants string
+	err := row.Scan(
+		&media.ID,
+		&media.Filename,
+		&media.Path,
+		&media.ContentType,
+		&media.Width,
+		&media.Height,
+		&media.Size,
+		&media.AltText,
+		&variants,
+		&media.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(variants), &media.Variants); err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -2,23 +2,28 @@
 package repository
 
 import (
+	"blog-portfolio/internal/database"
 	"blog-portfolio/internal/models"
+	"blog-portfolio/internal/utils"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"strings"
+	"time"
 )
 
-type PostRepository struct {
-	db *sql.DB
+// postRepository implements PostRepository for every supported database
+type postRepository struct {
+	db *database.Database
 }
 
-func NewPostRepository(db *sql.DB) *PostRepository {
-	return &PostRepository{db: db}
+func NewPostRepository(db *database.Database) PostRepository {
+	return &postRepository{db: db}
 }
 
-// CreatePost creates a new blog post
-func (r *PostRepository) CreatePost(ctx context.Context, post *models.Post) error {
+// CreatePost creates a new blog post and records its first revision
+func (r *postRepository) CreatePost(ctx context.Context, post *models.Post, info models.RevisionInfo) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -27,8 +32,9 @@ func (r *PostRepository) CreatePost(ctx context.Context, post *models.Post) erro
 
 	// Insert post
 	query := `
-        INSERT INTO posts (title, slug, content, description, cover_image, published, published_at)
-        VALUES (?, ?, ?, ?, ?, ?, ?)
+        INSERT INTO posts (title, slug, content, description, cover_image, published, published_at,
+                           scheduled_at, content_html, toc, reading_time, hide_toc, author_id)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
         RETURNING id, created_at, updated_at`
 
 	var publishedAt sql.NullTime
@@ -37,6 +43,18 @@ func (r *PostRepository) CreatePost(ctx context.Context, post *models.Post) erro
 		publishedAt.Valid = true
 	}
 
+	// Schedules are stored in UTC so they compare correctly in SQL
+	var scheduledAt sql.NullTime
+	if post.ScheduledAt != nil {
+		scheduledAt.Time = post.ScheduledAt.UTC()
+		scheduledAt.Valid = true
+	}
+
+	toc, err := encodeTOC(post.TOC)
+	if err != nil {
+		return err
+	}
+
 	err = tx.QueryRowContext(
 		ctx,
 		query,
@@ -47,6 +65,12 @@ func (r *PostRepository) CreatePost(ctx context.Context, post *models.Post) erro
 		post.CoverImage,
 		post.Published,
 		publishedAt,
+		scheduledAt,
+		post.ContentHTML,
+		toc,
+		post.ReadingTime,
+		post.HideTOC,
+		post.AuthorID,
 	).Scan(&post.ID, &post.CreatedAt, &post.UpdatedAt)
 	if err != nil {
 		return err
@@ -83,21 +107,28 @@ func (r *PostRepository) CreatePost(ctx context.Context, post *models.Post) erro
 		}
 	}
 
+	if err := createRevisionTx(ctx, tx, post, info); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
 // GetPost retrieves a post by its slug
-func (r *PostRepository) GetPost(ctx context.Context, slug string) (*models.Post, error) {
+func (r *postRepository) GetPost(ctx context.Context, slug string) (*models.Post, error) {
 	post := &models.Post{}
 	query := `
         SELECT 
             p.id, p.title, p.slug, p.content, p.description, 
             p.cover_image, p.published, p.created_at, p.updated_at, 
-            p.published_at 
-        FROM posts p 
-        WHERE p.slug = ?`
-
-	var publishedAt sql.NullTime
+            p.published_at, p.scheduled_at, p.content_html, p.toc, p.reading_time, p.hide_toc,
+            ` + authorColumns + `
+        FROM posts p ` + authorJoin + `
+        WHERE p.slug = ? AND p.deleted_at IS NULL`
+
+	var publishedAt, scheduledAt sql.NullTime
+	var toc string
+	var author postAuthor
 	err := r.db.QueryRowContext(ctx, query, slug).Scan(
 		&post.ID,
 		&post.Title,
@@ -109,6 +140,16 @@ func (r *PostRepository) GetPost(ctx context.Context, slug string) (*models.Post
 		&post.CreatedAt,
 		&post.UpdatedAt,
 		&publishedAt,
+		&scheduledAt,
+		&post.ContentHTML,
+		&toc,
+		&post.ReadingTime,
+		&post.HideTOC,
+		&author.id,
+		&author.username,
+		&author.displayName,
+		&author.bio,
+		&author.avatar,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -117,9 +158,18 @@ func (r *PostRepository) GetPost(ctx context.Context, slug string) (*models.Post
 		return nil, err
 	}
 
+	author.apply(post)
 	if publishedAt.Valid {
 		post.PublishedAt = &publishedAt.Time
 	}
+	if scheduledAt.Valid {
+		post.ScheduledAt = &scheduledAt.Time
+	}
+
+	post.TOC, err = decodeTOC(toc)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get tags
 	post.Tags, err = r.getPostTags(ctx, post.ID)
@@ -133,25 +183,25 @@ func (r *PostRepository) GetPost(ctx context.Context, slug string) (*models.Post
 // ListPosts returns a list of posts based on the filter
 // internal/repository/post_repository.go
 
-func (r *PostRepository) ListPosts(ctx context.Context, filter models.PostFilter) ([]*models.Post, error) {
+func (r *postRepository) ListPosts(ctx context.Context, filter models.PostFilter) ([]*models.Post, error) {
 	query := strings.Builder{}
 	query.WriteString(`
-        SELECT DISTINCT
+        SELECT
             p.id, p.title, p.slug, p.content, p.description, 
             p.cover_image, p.published, p.created_at, 
-            p.updated_at, p.published_at
-        FROM posts p
+            p.updated_at, p.published_at, p.scheduled_at, p.deleted_at, p.content_html, p.reading_time,
+            ` + authorColumns + `
+        FROM posts p ` + authorJoin + `
     `)
 
 	args := []interface{}{}
 	where := []string{}
 
 	if filter.Tag != "" {
-		query.WriteString(` 
-            LEFT JOIN post_tags pt ON p.id = pt.post_id 
-            LEFT JOIN tags t ON pt.tag_id = t.id
-        `)
-		where = append(where, "t.slug = ?")
+		where = append(where, `EXISTS (
+            SELECT 1 FROM post_tags pt
+            JOIN tags t ON pt.tag_id = t.id
+            WHERE pt.post_id = p.id AND t.slug = ?)`)
 		args = append(args, filter.Tag)
 	}
 
@@ -161,12 +211,26 @@ func (r *PostRepository) ListPosts(ctx context.Context, filter models.PostFilter
 		args = append(args, *filter.Published)
 	}
 
-	if len(where) > 0 {
-		query.WriteString(" WHERE " + strings.Join(where, " AND "))
+	if filter.AuthorID != 0 {
+		where = append(where, "p.author_id = ?")
+		args = append(args, filter.AuthorID)
+	}
+
+	// Trashed posts only ever show up in the trash
+	if filter.Trashed {
+		where = append(where, "p.deleted_at IS NOT NULL")
+	} else {
+		where = append(where, "p.deleted_at IS NULL")
 	}
 
-	// Order by published date for published posts, creation date for drafts
-	query.WriteString(" ORDER BY CASE WHEN p.published = 1 THEN p.published_at ELSE p.created_at END DESC")
+	query.WriteString(" WHERE " + strings.Join(where, " AND "))
+
+	if filter.Trashed {
+		query.WriteString(" ORDER BY p.deleted_at DESC")
+	} else {
+		// Order by published date for published posts, creation date for drafts
+		query.WriteString(" ORDER BY CASE WHEN p.published = TRUE THEN p.published_at ELSE p.created_at END DESC")
+	}
 
 	if filter.Limit > 0 {
 		query.WriteString(" LIMIT ?")
@@ -186,7 +250,8 @@ func (r *PostRepository) ListPosts(ctx context.Context, filter models.PostFilter
 	var posts []*models.Post
 	for rows.Next() {
 		post := &models.Post{}
-		var publishedAt sql.NullTime
+		var publishedAt, scheduledAt, deletedAt sql.NullTime
+		var author postAuthor
 		err := rows.Scan(
 			&post.ID,
 			&post.Title,
@@ -198,34 +263,68 @@ func (r *PostRepository) ListPosts(ctx context.Context, filter models.PostFilter
 			&post.CreatedAt,
 			&post.UpdatedAt,
 			&publishedAt,
+			&scheduledAt,
+			&deletedAt,
+			&post.ContentHTML,
+			&post.ReadingTime,
+			&author.id,
+			&author.username,
+			&author.displayName,
+			&author.bio,
+			&author.avatar,
 		)
 		if err != nil {
 			return nil, err
 		}
 
+		author.apply(post)
 		if publishedAt.Valid {
 			post.PublishedAt = &publishedAt.Time
 		}
+		if scheduledAt.Valid {
+			post.ScheduledAt = &scheduledAt.Time
+		}
+		if deletedAt.Valid {
+			post.DeletedAt = &deletedAt.Time
+		}
 
 		posts = append(posts, post)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Load tags once the result rows are closed
+	if filter.WithTags {
+		for _, post := range posts {
+			post.Tags, err = r.getPostTags(ctx, post.ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
 
 	return posts, nil
 }
 
-// UpdatePost updates an existing post
-func (r *PostRepository) UpdatePost(ctx context.Context, post *models.Post) error {
+// UpdatePost updates an existing post and records the new state as a revision
+func (r *postRepository) UpdatePost(ctx context.Context, post *models.Post, info models.RevisionInfo) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	if err := recordSlugChangeTx(ctx, tx, post); err != nil {
+		return err
+	}
+
 	// Update post
 	query := `
         UPDATE posts 
-        SET title = ?, content = ?, description = ?, 
-            cover_image = ?, published = ?, published_at = ?,
+        SET title = ?, slug = ?, content = ?, description = ?, 
+            cover_image = ?, published = ?, published_at = ?, scheduled_at = ?,
+            content_html = ?, toc = ?, reading_time = ?, hide_toc = ?,
             updated_at = CURRENT_TIMESTAMP
         WHERE id = ?`
 
@@ -235,15 +334,33 @@ func (r *PostRepository) UpdatePost(ctx context.Context, post *models.Post) erro
 		publishedAt.Valid = true
 	}
 
+	// Schedules are stored in UTC so they compare correctly in SQL
+	var scheduledAt sql.NullTime
+	if post.ScheduledAt != nil {
+		scheduledAt.Time = post.ScheduledAt.UTC()
+		scheduledAt.Valid = true
+	}
+
+	toc, err := encodeTOC(post.TOC)
+	if err != nil {
+		return err
+	}
+
 	result, err := tx.ExecContext(
 		ctx,
 		query,
 		post.Title,
+		post.Slug,
 		post.Content,
 		post.Description,
 		post.CoverImage,
 		post.Published,
 		publishedAt,
+		scheduledAt,
+		post.ContentHTML,
+		toc,
+		post.ReadingTime,
+		post.HideTOC,
 		post.ID,
 	)
 	if err != nil {
@@ -290,12 +407,132 @@ func (r *PostRepository) UpdatePost(ctx context.Context, post *models.Post) erro
 		}
 	}
 
+	if err := createRevisionTx(ctx, tx, post, info); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
-// DeletePost deletes a post by ID
-func (r *PostRepository) DeletePost(ctx context.Context, id int64) error {
-	result, err := r.db.ExecContext(ctx, "DELETE FROM posts WHERE id = ?", id)
+// recordSlugChangeTx keeps the post's current slug in the slug history when it is about to change,
+// so links to the old URL keep working
+func recordSlugChangeTx(ctx context.Context, tx *database.Tx, post *models.Post) error {
+	var current string
+	err := tx.QueryRowContext(ctx, "SELECT slug FROM posts WHERE id = ?", post.ID).Scan(&current)
+	if err != nil {
+		return err
+	}
+	if current == post.Slug {
+		return nil
+	}
+
+	// Taking back a former slug turns it from a redirect into the live URL again
+	if _, err := tx.ExecContext(ctx, "DELETE FROM post_slug_history WHERE slug = ?", post.Slug); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO post_slug_history (slug, post_id)
+        VALUES (?, ?)
+        ON CONFLICT(slug) DO UPDATE SET post_id = excluded.post_id, created_at = CURRENT_TIMESTAMP`,
+		current,
+		post.ID,
+	)
+	return err
+}
+
+// GetRedirectSlug returns the current slug of the post that formerly used slug,
+// or an empty string if no post has used it
+func (r *postRepository) GetRedirectSlug(ctx context.Context, slug string) (string, error) {
+	var current string
+	err := r.db.QueryRowContext(ctx, `
+        SELECT p.slug
+        FROM post_slug_history h
+        JOIN posts p ON p.id = h.post_id
+        WHERE h.slug = ? AND p.deleted_at IS NULL`,
+		slug,
+	).Scan(&current)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+	return current, nil
+}
+
+// SlugTaken reports whether slug is used by any post other than excludeID, either as its
+// current slug or as a former slug that still redirects to it
+func (r *postRepository) SlugTaken(ctx context.Context, slug string, excludeID int64) (bool, error) {
+	var taken bool
+	err := r.db.QueryRowContext(ctx, `
+        SELECT EXISTS (SELECT 1 FROM posts WHERE slug = ? AND id != ?)
+            OR EXISTS (SELECT 1 FROM post_slug_history WHERE slug = ? AND post_id != ?)`,
+		slug, excludeID,
+		slug, excludeID,
+	).Scan(&taken)
+	return taken, err
+}
+
+// DeletePost moves a post to the trash
+func (r *postRepository) DeletePost(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE posts SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL",
+		time.Now().UTC(),
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// RestorePost moves a post out of the trash
+func (r *postRepository) RestorePost(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE posts SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL",
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// PurgeTrashedBefore permanently deletes posts trashed before the given time.
+// Tag links and revisions are removed with them by ON DELETE CASCADE.
+func (r *postRepository) PurgeTrashedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM posts WHERE deleted_at IS NOT NULL AND deleted_at < ?",
+		before.UTC(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// PurgePost permanently deletes a trashed post
+func (r *postRepository) PurgePost(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM posts WHERE id = ? AND deleted_at IS NOT NULL", id)
 	if err != nil {
 		return err
 	}
@@ -311,8 +548,36 @@ func (r *PostRepository) DeletePost(ctx context.Context, id int64) error {
 	return nil
 }
 
+// authorColumns and authorJoin add a post's author to a query on posts p, scanned
+// into a postAuthor. Posts whose author was deleted have NULLs.
+const (
+	authorColumns = "u.id, u.username, u.display_name, u.bio, u.avatar"
+	authorJoin    = "LEFT JOIN users u ON u.id = p.author_id"
+)
+
+// postAuthor holds the author columns of a post row
+type postAuthor struct {
+	id                                 sql.NullInt64
+	username, displayName, bio, avatar sql.NullString
+}
+
+// apply sets the post's author, if it has one
+func (a *postAuthor) apply(post *models.Post) {
+	if !a.id.Valid {
+		return
+	}
+	post.AuthorID = &a.id.Int64
+	post.Author = &models.Author{
+		ID:          a.id.Int64,
+		Username:    a.username.String,
+		DisplayName: a.displayName.String,
+		Bio:         a.bio.String,
+		Avatar:      a.avatar.String,
+	}
+}
+
 // getPostTags retrieves all tags for a given post
-func (r *PostRepository) getPostTags(ctx context.Context, postID int64) ([]models.Tag, error) {
+func (r *postRepository) getPostTags(ctx context.Context, postID int64) ([]models.Tag, error) {
 	rows, err := r.db.QueryContext(ctx, `
         SELECT t.id, t.name, t.slug, t.created_at
         FROM tags t
@@ -339,23 +604,99 @@ func (r *PostRepository) getPostTags(ctx context.Context, postID int64) ([]model
 	return tags, nil
 }
 
-// internal/repository/post_repository.go
+// PublishScheduled publishes every post whose scheduled time is at or before now,
+// using the scheduled time as its publish date. It returns the number of posts published.
+func (r *postRepository) PublishScheduled(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+        UPDATE posts
+        SET published = TRUE,
+            published_at = scheduled_at,
+            scheduled_at = NULL,
+            updated_at = CURRENT_TIMESTAMP
+        WHERE scheduled_at IS NOT NULL AND scheduled_at <= ? AND deleted_at IS NULL`,
+		now.UTC(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// NextScheduledAt returns the earliest pending publish time, or nil if nothing is scheduled
+func (r *postRepository) NextScheduledAt(ctx context.Context) (*time.Time, error) {
+	var scheduledAt time.Time
+	err := r.db.QueryRowContext(ctx, `
+        SELECT scheduled_at
+        FROM posts
+        WHERE scheduled_at IS NOT NULL AND deleted_at IS NULL
+        ORDER BY scheduled_at
+        LIMIT 1`,
+	).Scan(&scheduledAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &scheduledAt, nil
+}
 
-// BeginTx starts a new transaction
-func (r *PostRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
-	return r.db.BeginTx(ctx, nil)
+// UpdateRenderedContent stores freshly rendered output without touching updated_at
+func (r *postRepository) UpdateRenderedContent(ctx context.Context, post *models.Post) error {
+	toc, err := encodeTOC(post.TOC)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		"UPDATE posts SET content_html = ?, toc = ?, reading_time = ? WHERE id = ?",
+		post.ContentHTML,
+		toc,
+		post.ReadingTime,
+		post.ID,
+	)
+	return err
+}
+
+// encodeTOC serializes a table of contents for storage
+func encodeTOC(toc []utils.TOCEntry) (string, error) {
+	if toc == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(toc)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// decodeTOC parses a stored table of contents
+func decodeTOC(data string) ([]utils.TOCEntry, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var toc []utils.TOCEntry
+	if err := json.Unmarshal([]byte(data), &toc); err != nil {
+		return nil, err
+	}
+	return toc, nil
 }
 
 // GetPostByID retrieves a post by its ID
-func (r *PostRepository) GetPostByID(ctx context.Context, id int64) (*models.Post, error) {
+func (r *postRepository) GetPostByID(ctx context.Context, id int64) (*models.Post, error) {
 	post := &models.Post{}
 	query := `
-        SELECT id, title, slug, content, description, cover_image, 
-               published, created_at, updated_at, published_at
-        FROM posts
-        WHERE id = ?`
-
-	var publishedAt sql.NullTime
+        SELECT p.id, p.title, p.slug, p.content, p.description, p.cover_image,
+               p.published, p.created_at, p.updated_at, p.published_at, p.scheduled_at,
+               p.content_html, p.toc, p.reading_time, p.hide_toc,
+               ` + authorColumns + `
+        FROM posts p ` + authorJoin + `
+        WHERE p.id = ?`
+
+	var publishedAt, scheduledAt sql.NullTime
+	var toc string
+	var author postAuthor
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&post.ID,
 		&post.Title,
@@ -367,6 +708,16 @@ func (r *PostRepository) GetPostByID(ctx context.Context, id int64) (*models.Pos
 		&post.CreatedAt,
 		&post.UpdatedAt,
 		&publishedAt,
+		&scheduledAt,
+		&post.ContentHTML,
+		&toc,
+		&post.ReadingTime,
+		&post.HideTOC,
+		&author.id,
+		&author.username,
+		&author.displayName,
+		&author.bio,
+		&author.avatar,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -375,9 +726,18 @@ func (r *PostRepository) GetPostByID(ctx context.Context, id int64) (*models.Pos
 		return nil, err
 	}
 
+	author.apply(post)
 	if publishedAt.Valid {
 		post.PublishedAt = &publishedAt.Time
 	}
+	if scheduledAt.Valid {
+		post.ScheduledAt = &scheduledAt.Time
+	}
+
+	post.TOC, err = decodeTOC(toc)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get tags
 	post.Tags, err = r.getPostTags(ctx, post.ID)
@@ -389,7 +749,7 @@ func (r *PostRepository) GetPostByID(ctx context.Context, id int64) (*models.Pos
 }
 
 // ListTags returns all available tags
-func (r *PostRepository) ListTags(ctx context.Context) ([]models.Tag, error) {
+func (r *postRepository) ListTags(ctx context.Context) ([]models.Tag, error) {
 	query := `
         SELECT id, name, slug, created_at
         FROM tags
@@ -414,100 +774,90 @@ func (r *PostRepository) ListTags(ctx context.Context) ([]models.Tag, error) {
 	return tags, nil
 }
 
-// CreatePostTx creates a new post within a transaction
-func (r *PostRepository) CreatePostTx(ctx context.Context, tx *sql.Tx, post *models.Post) error {
-	query := `
-        INSERT INTO posts (title, slug, content, description, cover_image, published, published_at)
-        VALUES (?, ?, ?, ?, ?, ?, ?)
-        RETURNING id, created_at, updated_at`
-
-	var publishedAt sql.NullTime
-	if post.PublishedAt != nil {
-		publishedAt.Time = *post.PublishedAt
-		publishedAt.Valid = true
-	}
-
-	err := tx.QueryRowContext(
+// createRevisionTx snapshots the post's current content within a transaction
+func createRevisionTx(ctx context.Context, tx *database.Tx, post *models.Post, info models.RevisionInfo) error {
+	_, err := tx.ExecContext(
 		ctx,
-		query,
+		`INSERT INTO post_revisions (post_id, title, slug, content, description, cover_image, author, note)
+         VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		post.ID,
 		post.Title,
 		post.Slug,
 		post.Content,
 		post.Description,
 		post.CoverImage,
-		post.Published,
-		publishedAt,
-	).Scan(&post.ID, &post.CreatedAt, &post.UpdatedAt)
-
+		info.Author,
+		info.Note,
+	)
 	return err
 }
 
-// UpdatePostTx updates an existing post within a transaction
-func (r *PostRepository) UpdatePostTx(ctx context.Context, tx *sql.Tx, post *models.Post) error {
-	query := `
-        UPDATE posts 
-        SET title = ?, content = ?, description = ?, 
-            cover_image = ?, published = ?, published_at = ?,
-            updated_at = CURRENT_TIMESTAMP
-        WHERE id = ?`
-
-	var publishedAt sql.NullTime
-	if post.PublishedAt != nil {
-		publishedAt.Time = *post.PublishedAt
-		publishedAt.Valid = true
-	}
-
-	result, err := tx.ExecContext(
-		ctx,
-		query,
-		post.Title,
-		post.Content,
-		post.Description,
-		post.CoverImage,
-		post.Published,
-		publishedAt,
-		post.ID,
+// ListRevisions returns a post's revisions, newest first
+func (r *postRepository) ListRevisions(ctx context.Context, postID int64) ([]models.PostRevision, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT id, post_id, title, slug, content, COALESCE(description, ''),
+               COALESCE(cover_image, ''), author, note, created_at
+        FROM post_revisions
+        WHERE post_id = ?
+        ORDER BY id DESC`,
+		postID,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-	if rows == 0 {
-		return sql.ErrNoRows
+	var revisions []models.PostRevision
+	for rows.Next() {
+		var rev models.PostRevision
+		err := rows.Scan(
+			&rev.ID,
+			&rev.PostID,
+			&rev.Title,
+			&rev.Slug,
+			&rev.Content,
+			&rev.Description,
+			&rev.CoverImage,
+			&rev.Author,
+			&rev.Note,
+			&rev.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
 	}
 
-	return nil
+	return revisions, rows.Err()
 }
 
-// SetPostTagsTx updates a post's tags within a transaction
-func (r *PostRepository) SetPostTagsTx(ctx context.Context, tx *sql.Tx, postID int64, tagIDs []int64) error {
-	// First, remove all existing tag associations
-	_, err := tx.ExecContext(ctx, "DELETE FROM post_tags WHERE post_id = ?", postID)
+// GetRevision retrieves a single revision by its ID
+func (r *postRepository) GetRevision(ctx context.Context, id int64) (*models.PostRevision, error) {
+	var rev models.PostRevision
+	err := r.db.QueryRowContext(ctx, `
+        SELECT id, post_id, title, slug, content, COALESCE(description, ''),
+               COALESCE(cover_image, ''), author, note, created_at
+        FROM post_revisions
+        WHERE id = ?`,
+		id,
+	).Scan(
+		&rev.ID,
+		&rev.PostID,
+		&rev.Title,
+		&rev.Slug,
+		&rev.Content,
+		&rev.Description,
+		&rev.CoverImage,
+		&rev.Author,
+		&rev.Note,
+		&rev.CreatedAt,
+	)
 	if err != nil {
-		return err
-	}
-
-	// Then add new tag associations
-	if len(tagIDs) > 0 {
-		query := "INSERT INTO post_tags (post_id, tag_id) VALUES "
-		var values []interface{}
-		placeholders := make([]string, 0, len(tagIDs))
-
-		for _, tagID := range tagIDs {
-			placeholders = append(placeholders, "(?, ?)")
-			values = append(values, postID, tagID)
-		}
-
-		query += strings.Join(placeholders, ", ")
-		_, err = tx.ExecContext(ctx, query, values...)
-		if err != nil {
-			return err
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
 		}
+		return nil, err
 	}
 
-	return nil
+	return &rev, nil
 }
@@ -0,0 +1,201 @@
+// internal/repository/post_search.go
+package repository
+
+import (
+	"blog-portfolio/internal/database"
+	"blog-portfolio/internal/models"
+	"context"
+	"database/sql"
+	"html"
+	"strings"
+)
+
+// Search markers wrapped around matched terms in snippets, replaced after HTML escaping
+const (
+	searchMatchStart = "\x02"
+	searchMatchEnd   = "\x03"
+)
+
+// Both search queries return the same columns, with the best matches first. SQLite
+// searches the FTS5 posts_fts table, Postgres the generated posts.search_vector.
+const (
+	sqliteSearchQuery = `
+        SELECT
+            p.id, p.title, p.slug, p.description, p.cover_image,
+            p.published, p.created_at, p.updated_at, p.published_at,
+            p.reading_time,
+            snippet(posts_fts, -1, char(2), char(3), '…', 24),
+            bm25(posts_fts, 10.0, 5.0, 1.0, 3.0) AS rank
+        FROM posts_fts
+        JOIN posts p ON p.id = posts_fts.rowid
+        WHERE posts_fts MATCH ? AND p.published = TRUE AND p.deleted_at IS NULL
+        ORDER BY rank
+        LIMIT ? OFFSET ?`
+
+	postgresSearchQuery = `
+        SELECT
+            p.id, p.title, p.slug, p.description, p.cover_image,
+            p.published, p.created_at, p.updated_at, p.published_at,
+            p.reading_time,
+            ts_headline('english', p.content, q, ?),
+            ts_rank(p.search_vector, q) AS rank
+        FROM posts p, to_tsquery('english', ?) q
+        WHERE p.search_vector @@ q AND p.published = TRUE AND p.deleted_at IS NULL
+        ORDER BY rank DESC
+        LIMIT ? OFFSET ?`
+)
+
+// postgresHeadlineOptions shapes ts_headline snippets like SQLite's snippet()
+const postgresHeadlineOptions = "StartSel=" + searchMatchStart + ", StopSel=" + searchMatchEnd +
+	", MaxFragments=1, MaxWords=24, MinWords=12, FragmentDelimiter=…"
+
+// Search runs a full-text query over published posts and returns the best matches
+// first. Every term must match, and the last is matched as a prefix so results update
+// while the reader is still typing.
+func (r *postRepository) Search(ctx context.Context, terms []string, limit, offset int) ([]models.SearchResult, error) {
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	var rows *sql.Rows
+	var err error
+	if r.db.Driver() == database.Postgres {
+		rows, err = r.db.QueryContext(ctx, postgresSearchQuery, postgresHeadlineOptions, tsQuery(terms), limit, offset)
+	} else {
+		rows, err = r.db.QueryContext(ctx, sqliteSearchQuery, ftsQuery(terms), limit, offset)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []models.SearchResult
+	for rows.Next() {
+		post := &models.Post{}
+		var publishedAt sql.NullTime
+		var snippet string
+		var rank float64
+		err := rows.Scan(
+			&post.ID,
+			&post.Title,
+			&post.Slug,
+			&post.Description,
+			&post.CoverImage,
+			&post.Published,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+			&publishedAt,
+			&post.ReadingTime,
+			&snippet,
+			&rank,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if publishedAt.Valid {
+			post.PublishedAt = &publishedAt.Time
+		}
+
+		results = append(results, models.SearchResult{
+			Post:    post,
+			Snippet: highlightSnippet(snippet),
+			Rank:    rank,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Load tags once the result rows are closed
+	for _, result := range results {
+		result.Post.Tags, err = r.getPostTags(ctx, result.Post.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// ftsQuery builds an FTS5 expression requiring every term. Each term is quoted so it
+// can't be read as query syntax.
+func ftsQuery(terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	quoted[len(quoted)-1] += "*"
+	return strings.Join(quoted, " ")
+}
+
+// tsQuery builds a Postgres tsquery requiring every term. Terms are letters and
+// digits only, so they can't be read as query syntax.
+func tsQuery(terms []string) string {
+	return strings.Join(terms, " & ") + ":*"
+}
+
+// highlightSnippet escapes a raw search snippet and turns the match markers into <mark> tags
+func highlightSnippet(snippet string) string {
+	// Collapse Markdown line breaks so the excerpt reads as one paragraph
+	escaped := html.EscapeString(strings.Join(strings.Fields(snippet), " "))
+	return strings.NewReplacer(
+		searchMatchStart, "<mark>",
+		searchMatchEnd, "</mark>",
+	).Replace(escaped)
+}
+
+// RebuildSearchIndex repopulates the full-text search index from the posts table,
+// returning how many posts were indexed
+func (r *postRepository) RebuildSearchIndex(ctx context.Context) (int64, error) {
+	// Postgres derives the index from the posts row; only the copied tag names can drift
+	if r.db.Driver() == database.Postgres {
+		result, err := r.db.ExecContext(ctx, `
+            UPDATE posts p
+            SET search_tags = COALESCE((
+                SELECT string_agg(t.name, ' ')
+                FROM post_tags pt
+                JOIN tags t ON t.id = pt.tag_id
+                WHERE pt.post_id = p.id
+            ), '')`)
+		if err != nil {
+			return 0, err
+		}
+		return result.RowsAffected()
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM posts_fts"); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+        INSERT INTO posts_fts (rowid, title, description, content, tags)
+        SELECT
+            p.id,
+            p.title,
+            COALESCE(p.description, ''),
+            p.content,
+            COALESCE((
+                SELECT group_concat(t.name, ' ')
+                FROM post_tags pt
+                JOIN tags t ON t.id = pt.tag_id
+                WHERE pt.post_id = p.id
+            ), '')
+        FROM posts p
+    `)
+	if err != nil {
+		return 0, err
+	}
+	indexed, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return indexed, tx.Commit()
+}
@@ -0,0 +1,103 @@
+// internal/repository/preview_repository.go
+package repository
+
+import (
+	"blog-portfolio/internal/database"
+	"blog-portfolio/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type PreviewRepository struct {
+	db *database.Database
+}
+
+func NewPreviewRepository(db *database.Database) *PreviewRepository {
+	return &PreviewRepository{db: db}
+}
+
+// CreateLink stores a new preview link
+func (r *PreviewRepository) CreateLink(ctx context.Context, link *models.PreviewLink) error {
+	return r.db.QueryRowContext(ctx, `
+        INSERT INTO post_preview_links (id, post_id, expires_at)
+        VALUES (?, ?, ?)
+        RETURNING created_at`,
+		link.ID,
+		link.PostID,
+		link.ExpiresAt.UTC(),
+	).Scan(&link.CreatedAt)
+}
+
+// GetLink retrieves a preview link by ID, returning nil if it doesn't exist
+func (r *PreviewRepository) GetLink(ctx context.Context, id string) (*models.PreviewLink, error) {
+	link := &models.PreviewLink{}
+	err := r.db.QueryRowContext(ctx, `
+        SELECT id, post_id, expires_at, created_at
+        FROM post_preview_links
+        WHERE id = ?`,
+		id,
+	).Scan(&link.ID, &link.PostID, &link.ExpiresAt, &link.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return link, nil
+}
+
+// ListLinks returns a post's preview links that are still valid at now, newest first
+func (r *PreviewRepository) ListLinks(ctx context.Context, postID int64, now time.Time) ([]models.PreviewLink, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT id, post_id, expires_at, created_at
+        FROM post_preview_links
+        WHERE post_id = ? AND expires_at > ?
+        ORDER BY created_at DESC`,
+		postID,
+		now.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []models.PreviewLink
+	for rows.Next() {
+		var link models.PreviewLink
+		if err := rows.Scan(&link.ID, &link.PostID, &link.ExpiresAt, &link.CreatedAt); err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// DeleteLink revokes a post's preview link
+func (r *PreviewRepository) DeleteLink(ctx context.Context, postID int64, id string) error {
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM post_preview_links WHERE id = ? AND post_id = ?",
+		id,
+		postID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteExpired removes links that expired before now
+func (r *PreviewRepository) DeleteExpired(ctx context.Context, now time.Time) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM post_preview_links WHERE expires_at <= ?", now.UTC())
+	return err
+}
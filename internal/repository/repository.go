@@ -0,0 +1,68 @@
+// internal/repository/repository.go
+package repository
+
+import (
+	"blog-portfolio/internal/models"
+	"context"
+	"time"
+)
+
+// PostRepository stores posts with their tags, revisions, slug redirects and search
+// index. Lookups return nil without an error when nothing matches.
+type PostRepository interface {
+	CreatePost(ctx context.Context, post *models.Post, info models.RevisionInfo) error
+	GetPost(ctx context.Context, slug string) (*models.Post, error)
+	GetPostByID(ctx context.Context, id int64) (*models.Post, error)
+	ListPosts(ctx context.Context, filter models.PostFilter) ([]*models.Post, error)
+	UpdatePost(ctx context.Context, post *models.Post, info models.RevisionInfo) error
+	UpdateRenderedContent(ctx context.Context, post *models.Post) error
+	SlugTaken(ctx context.Context, slug string, excludeID int64) (bool, error)
+	GetRedirectSlug(ctx context.Context, slug string) (string, error)
+
+	DeletePost(ctx context.Context, id int64) error
+	RestorePost(ctx context.Context, id int64) error
+	PurgePost(ctx context.Context, id int64) error
+	PurgeTrashedBefore(ctx context.Context, before time.Time) (int64, error)
+
+	PublishScheduled(ctx context.Context, now time.Time) (int64, error)
+	NextScheduledAt(ctx context.Context) (*time.Time, error)
+
+	ListTags(ctx context.Context) ([]models.Tag, error)
+	ListRevisions(ctx context.Context, postID int64) ([]models.PostRevision, error)
+	GetRevision(ctx context.Context, id int64) (*models.PostRevision, error)
+
+	Search(ctx context.Context, terms []string, limit, offset int) ([]models.SearchResult, error)
+	RebuildSearchIndex(ctx context.Context) (int64, error)
+}
+
+// TagRepository stores tags. Lookups return nil without an error when nothing matches.
+type TagRepository interface {
+	CreateTag(ctx context.Context, tag *models.Tag) error
+	UpdateTag(ctx context.Context, tag *models.Tag) error
+	DeleteTag(ctx context.Context, id int64) error
+	GetTagByID(ctx context.Context, id int64) (*models.Tag, error)
+	GetTagBySlug(ctx context.Context, slug string) (*models.Tag, error)
+	ListTags(ctx context.Context) ([]models.Tag, error)
+	ListPublishedTags(ctx context.Context) ([]models.Tag, error)
+}
+
+// UserRepository stores admin accounts. Lookups return nil without an error when
+// nothing matches.
+type UserRepository interface {
+	CreateUser(ctx context.Context, user *models.User) error
+	CreateFirstUser(ctx context.Context, user *models.User) error
+	GetUserByID(ctx context.Context, id int64) (*models.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	ListUsers(ctx context.Context) ([]models.User, error)
+	CountUsers(ctx context.Context) (int, error)
+	UpdatePassword(ctx context.Context, id int64, passwordHash string) error
+	UpdateProfile(ctx context.Context, user *models.User) error
+	UpdateRole(ctx context.Context, id int64, role string) error
+
+	EnableTOTP(ctx context.Context, id int64, secret string, step int64, codeHashes []string) error
+	DisableTOTP(ctx context.Context, id int64) error
+	UseTOTPStep(ctx context.Context, id int64, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, id int64, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, id int64, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, id int64) (int, error)
+}
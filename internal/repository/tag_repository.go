@@ -2,49 +2,51 @@
 package repository
 
 import (
+	"blog-portfolio/internal/database"
 	"blog-portfolio/internal/models"
+	"blog-portfolio/internal/slug"
 	"context"
 	"database/sql"
-	"strings"
 )
 
-type TagRepository struct {
-	db *sql.DB
+// tagRepository implements TagRepository for every supported database
+type tagRepository struct {
+	db *database.Database
 }
 
-func NewTagRepository(db *sql.DB) *TagRepository {
-	return &TagRepository{db: db}
+func NewTagRepository(db *database.Database) TagRepository {
+	return &tagRepository{db: db}
 }
 
-func (r *TagRepository) CreateTag(ctx context.Context, tag *models.Tag) error {
+func (r *tagRepository) CreateTag(ctx context.Context, tag *models.Tag) error {
 	query := `
         INSERT INTO tags (name, slug) 
         VALUES (?, ?)
         RETURNING id, created_at`
 
 	// Generate slug from name
-	slug := generateSlug(tag.Name)
+	tag.Slug = slug.Make(tag.Name)
 
 	err := r.db.QueryRowContext(
 		ctx,
 		query,
 		tag.Name,
-		slug,
+		tag.Slug,
 	).Scan(&tag.ID, &tag.CreatedAt)
 
 	return err
 }
 
-func (r *TagRepository) UpdateTag(ctx context.Context, tag *models.Tag) error {
+func (r *tagRepository) UpdateTag(ctx context.Context, tag *models.Tag) error {
 	query := `
         UPDATE tags 
         SET name = ?, slug = ?
         WHERE id = ?`
 
 	// Generate new slug from updated name
-	slug := generateSlug(tag.Name)
+	tag.Slug = slug.Make(tag.Name)
 
-	result, err := r.db.ExecContext(ctx, query, tag.Name, slug, tag.ID)
+	result, err := r.db.ExecContext(ctx, query, tag.Name, tag.Slug, tag.ID)
 	if err != nil {
 		return err
 	}
@@ -60,7 +62,7 @@ func (r *TagRepository) UpdateTag(ctx context.Context, tag *models.Tag) error {
 	return nil
 }
 
-func (r *TagRepository) DeleteTag(ctx context.Context, id int64) error {
+func (r *tagRepository) DeleteTag(ctx context.Context, id int64) error {
 	// Start a transaction since we need to delete from multiple tables
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -91,7 +93,7 @@ func (r *TagRepository) DeleteTag(ctx context.Context, id int64) error {
 	return tx.Commit()
 }
 
-func (r *TagRepository) ListTags(ctx context.Context) ([]models.Tag, error) {
+func (r *tagRepository) ListTags(ctx context.Context) ([]models.Tag, error) {
 	query := `
         SELECT t.id, t.name, t.slug, t.created_at, COUNT(pt.post_id) as post_count
         FROM tags t
@@ -126,7 +128,7 @@ func (r *TagRepository) ListTags(ctx context.Context) ([]models.Tag, error) {
 	return tags, nil
 }
 
-func (r *TagRepository) GetTagByID(ctx context.Context, id int64) (*models.Tag, error) {
+func (r *tagRepository) GetTagByID(ctx context.Context, id int64) (*models.Tag, error) {
 	query := `
         SELECT id, name, slug, created_at
         FROM tags
@@ -149,21 +151,60 @@ func (r *TagRepository) GetTagByID(ctx context.Context, id int64) (*models.Tag,
 	return &tag, nil
 }
 
-// Helper function to generate URL-friendly slugs
-func generateSlug(name string) string {
-	slug := strings.ToLower(name)
-	slug = strings.ReplaceAll(slug, " ", "-")
-	// Remove any characters that aren't alphanumeric or hyphens
-	slug = strings.Map(func(r rune) rune {
-		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
-			return r
+func (r *tagRepository) GetTagBySlug(ctx context.Context, slug string) (*models.Tag, error) {
+	query := `
+        SELECT id, name, slug, created_at
+        FROM tags
+        WHERE slug = ?`
+
+	var tag models.Tag
+	err := r.db.QueryRowContext(ctx, query, slug).Scan(
+		&tag.ID,
+		&tag.Name,
+		&tag.Slug,
+		&tag.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+// ListPublishedTags returns the tags that have published posts, counting only those
+func (r *tagRepository) ListPublishedTags(ctx context.Context) ([]models.Tag, error) {
+	query := `
+        SELECT t.id, t.name, t.slug, t.created_at, COUNT(p.id) as post_count
+        FROM tags t
+        JOIN post_tags pt ON t.id = pt.tag_id
+        JOIN posts p ON p.id = pt.post_id AND p.published = TRUE AND p.deleted_at IS NULL
+        GROUP BY t.id, t.name, t.slug, t.created_at
+        ORDER BY t.name`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []models.Tag
+	for rows.Next() {
+		var tag models.Tag
+		err := rows.Scan(
+			&tag.ID,
+			&tag.Name,
+			&tag.Slug,
+			&tag.CreatedAt,
+			&tag.PostCount,
+		)
+		if err != nil {
+			return nil, err
 		}
-		return -1
-	}, slug)
-	// Remove multiple consecutive hyphens
-	for strings.Contains(slug, "--") {
-		slug = strings.ReplaceAll(slug, "--", "-")
-	}
-	// Trim hyphens from start and end
-	return strings.Trim(slug, "-")
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
 }
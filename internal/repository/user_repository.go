@@ -0,0 +1,316 @@
+// internal/repository/user_repository.go
+package repository
+
+import (
+	"blog-portfolio/internal/database"
+	"blog-portfolio/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// userRepository implements UserRepository for every supported database
+type userRepository struct {
+	db *database.Database
+}
+
+func NewUserRepository(db *database.Database) UserRepository {
+	return &userRepository{db: db}
+}
+
+// CreateUser stores a new user with an already hashed password
+func (r *userRepository) CreateUser(ctx context.Context, user *models.User) error {
+	return r.db.QueryRowContext(ctx, `
+        INSERT INTO users (username, password_hash, role, display_name, bio, avatar)
+        VALUES (?, ?, ?, ?, ?, ?)
+        RETURNING id, created_at, updated_at`,
+		user.Username,
+		user.PasswordHash,
+		user.Role,
+		user.DisplayName,
+		user.Bio,
+		user.Avatar,
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+}
+
+// CreateFirstUser stores the first account like CreateUser and, in the same
+// transaction, credits it with every post that has no author, such as those written
+// before accounts existed
+func (r *userRepository) CreateFirstUser(ctx context.Context, user *models.User) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+        INSERT INTO users (username, password_hash, role, display_name, bio, avatar)
+        VALUES (?, ?, ?, ?, ?, ?)
+        RETURNING id, created_at, updated_at`,
+		user.Username,
+		user.PasswordHash,
+		user.Role,
+		user.DisplayName,
+		user.Bio,
+		user.Avatar,
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE posts SET author_id = ? WHERE author_id IS NULL", user.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetUserByID retrieves a user by ID, returning nil if it doesn't exist
+func (r *userRepository) GetUserByID(ctx context.Context, id int64) (*models.User, error) {
+	return r.getUser(ctx, "id = ?", id)
+}
+
+// GetUserByUsername retrieves a user by username, ignoring case, returning nil if it
+// doesn't exist
+func (r *userRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.getUser(ctx, "LOWER(username) = LOWER(?)", username)
+}
+
+func (r *userRepository) getUser(ctx context.Context, where string, arg any) (*models.User, error) {
+	user := &models.User{}
+	err := r.db.QueryRowContext(ctx, `
+        SELECT id, username, password_hash, role, display_name, bio, avatar, totp_secret, created_at, updated_at
+        FROM users
+        WHERE `+where,
+		arg,
+	).Scan(
+		&user.ID,
+		&user.Username,
+		&user.PasswordHash,
+		&user.Role,
+		&user.DisplayName,
+		&user.Bio,
+		&user.Avatar,
+		&user.TOTPSecret,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// ListUsers returns every user by username, with how many posts each has written
+// that aren't in the trash
+func (r *userRepository) ListUsers(ctx context.Context) ([]models.User, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT u.id, u.username, u.role, u.display_name, u.bio, u.avatar, u.totp_secret, u.created_at, u.updated_at,
+               COUNT(p.id)
+        FROM users u
+        LEFT JOIN posts p ON p.author_id = u.id AND p.deleted_at IS NULL
+        GROUP BY u.id, u.username, u.role, u.display_name, u.bio, u.avatar, u.totp_secret, u.created_at, u.updated_at
+        ORDER BY LOWER(u.username)`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Role,
+			&user.DisplayName,
+			&user.Bio,
+			&user.Avatar,
+			&user.TOTPSecret,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.PostCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// CountUsers returns how many users exist
+func (r *userRepository) CountUsers(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&count)
+	return count, err
+}
+
+// UpdatePassword replaces a user's password hash
+func (r *userRepository) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	return r.update(ctx, id, "password_hash = ?", passwordHash)
+}
+
+// UpdateProfile replaces a user's public display name, bio and avatar
+func (r *userRepository) UpdateProfile(ctx context.Context, user *models.User) error {
+	return r.update(ctx, user.ID,
+		"display_name = ?, bio = ?, avatar = ?",
+		user.DisplayName, user.Bio, user.Avatar,
+	)
+}
+
+// UpdateRole changes a user's role
+func (r *userRepository) UpdateRole(ctx context.Context, id int64, role string) error {
+	return r.update(ctx, id, "role = ?", role)
+}
+
+// EnableTOTP turns on two-factor login with the given secret, replacing any recovery
+// codes with new ones. step is the time step of the code that confirmed the secret,
+// so it can't be used again to sign in.
+func (r *userRepository) EnableTOTP(ctx context.Context, id int64, secret string, step int64, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		"UPDATE users SET totp_secret = ?, totp_last_step = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		secret, step, id,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, id, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DisableTOTP turns off two-factor login and deletes the recovery codes
+func (r *userRepository) DisableTOTP(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		"UPDATE users SET totp_secret = '', totp_last_step = 0, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_recovery_codes WHERE user_id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UseTOTPStep records that a code from the given time step was accepted. It returns
+// false if a code from that step or a later one was already used.
+func (r *userRepository) UseTOTPStep(ctx context.Context, id int64, step int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE users SET totp_last_step = ? WHERE id = ? AND totp_last_step < ?",
+		step, id, step,
+	)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows == 1, err
+}
+
+// ReplaceRecoveryCodes discards a user's recovery codes and stores new ones
+func (r *userRepository) ReplaceRecoveryCodes(ctx context.Context, id int64, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, id, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *database.Tx, id int64, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_recovery_codes WHERE user_id = ?", id); err != nil {
+		return err
+	}
+	for _, hash := range codeHashes {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO user_recovery_codes (user_id, code_hash) VALUES (?, ?)",
+			id, hash,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UseRecoveryCode marks the recovery code with the given hash as used. It returns
+// false if the user has no such unused code.
+func (r *userRepository) UseRecoveryCode(ctx context.Context, id int64, codeHash string) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE user_recovery_codes SET used_at = CURRENT_TIMESTAMP WHERE user_id = ? AND code_hash = ? AND used_at IS NULL",
+		id, codeHash,
+	)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows == 1, err
+}
+
+// CountRecoveryCodes returns how many of a user's recovery codes are unused
+func (r *userRepository) CountRecoveryCodes(ctx context.Context, id int64) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = ? AND used_at IS NULL",
+		id,
+	).Scan(&count)
+	return count, err
+}
+
+// update sets columns on one user, returning sql.ErrNoRows if it doesn't exist
+func (r *userRepository) update(ctx context.Context, id int64, set string, args ...any) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE users SET "+set+", updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		append(args, id)...,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -6,8 +6,8 @@ import (
 	"blog-portfolio/internal/handlers"
 	"blog-portfolio/internal/logger"
 	custommw "blog-portfolio/internal/middleware"
+	"blog-portfolio/internal/models"
 	"net/http"
-	"time"
 
 	"github.com/go-chi/chi/v5"
 	chimw "github.com/go-chi/chi/v5/middleware"
@@ -34,7 +34,7 @@ func New(logger *logger.Logger, config *config.Config, handlers *handlers.Handle
 	r.Use(m.RequestLogger)
 	// r.Use(m.Recover)
 	r.Use(chimw.RealIP)
-	r.Use(chimw.Timeout(60 * time.Second))
+	r.Use(chimw.Timeout(config.Server.RequestTimeout()))
 
 	// CORS middleware
 	logger.Info("Configuring CORS with allowed origins:", config.Server.AllowOrigins)
@@ -66,7 +66,9 @@ func (router *Router) setupRoutes() {
 	r := router.Router
 
 	// Serve static files
+	r.Get("/static/css/highlight.css", router.handlers.HighlightCSS())
 	r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.Dir("web/static"))))
+	r.Get("/media/*", router.handlers.Media())
 
 	// Health check
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -77,17 +79,42 @@ func (router *Router) setupRoutes() {
 	r.Group(func(r chi.Router) {
 		r.Get("/login", router.handlers.Auth().ShowLogin())
 		r.Post("/login", router.handlers.Auth().HandleLogin())
+		r.Get("/login/2fa", router.handlers.Auth().ShowSecondFactor())
+		r.Post("/login/2fa", router.handlers.Auth().HandleSecondFactor())
 		r.Get("/logout", router.handlers.Auth().HandleLogout())
+		r.Get("/setup", router.handlers.Auth().ShowSetup())
+		r.Post("/setup", router.handlers.Auth().HandleSetup())
 	})
 
 	// Public routes
 	r.Get("/", router.handlers.Home())
 	r.Get("/blog", router.handlers.Posts().ListPosts())
 	r.Get("/blog/{slug}", router.handlers.Posts().GetPost())
+	r.Get("/search", router.handlers.Posts().Search())
+	r.Get("/search/results", router.handlers.Posts().SearchResults())
+	r.Get("/authors/{username}", router.handlers.Posts().GetAuthor())
+
+	// Crawlers
+	r.Get("/robots.txt", router.handlers.SEO().Robots())
+	r.Get("/sitemap.xml", router.handlers.SEO().Sitemap())
+	r.Get("/sitemap-{page:[0-9]+}.xml", router.handlers.SEO().SitemapPage())
+
+	// Share images
+	r.Get("/og/{slug}.png", router.handlers.SEO().OGImage())
+
+	// Feeds, site-wide and per tag
+	r.Get("/feed.xml", router.handlers.Feeds().RSS())
+	r.Get("/atom.xml", router.handlers.Feeds().Atom())
+	r.Get("/feed.json", router.handlers.Feeds().JSON())
+	r.Route("/tags/{slug}", func(r chi.Router) {
+		r.Get("/feed.xml", router.handlers.Feeds().RSS())
+		r.Get("/atom.xml", router.handlers.Feeds().Atom())
+		r.Get("/feed.json", router.handlers.Feeds().JSON())
+	})
 
 	// Admin routes - protected by RequireAuth middleware
 	r.Route("/admin", func(r chi.Router) {
-		r.Use(custommw.RequireAuth)
+		r.Use(router.handlers.Sessions().RequireAuth)
 		r.Post("/preview", router.handlers.Admin().HandlePreview())
 		// Dashboard
 		r.Get("/dashboard", router.handlers.Admin().ShowDashboard())
@@ -98,8 +125,60 @@ func (router *Router) setupRoutes() {
 			r.Get("/new/", router.handlers.Admin().ShowCreatePost())
 			r.Get("/{id}", router.handlers.Admin().ShowEditPost())
 			r.Post("/", router.handlers.Admin().HandleCreatePost())
+			r.With(custommw.RequireRole(models.CanManageSite)).Post("/rerender", router.handlers.Admin().HandleRerenderPosts())
 			r.Put("/{id}", router.handlers.Admin().HandleUpdatePost())
+			r.Post("/{id}", router.handlers.Admin().HandleUpdatePost()) // editor form submits via POST
+			r.Get("/{id}/revisions", router.handlers.Admin().ShowRevisions())
+			r.Post("/{id}/revisions/{revisionID}/restore", router.handlers.Admin().HandleRestoreRevision())
+			r.Post("/{id}/previews", router.handlers.Admin().HandleCreatePreviewLink())
+			r.Delete("/{id}/previews/{linkID}", router.handlers.Admin().HandleRevokePreviewLink())
 			r.Delete("/{id}", router.handlers.Admin().HandleDeletePost())
 		})
+
+		// Media library
+		r.Route("/media", func(r chi.Router) {
+			r.Get("/", router.handlers.Admin().ShowMedia())
+			r.Get("/picker", router.handlers.Admin().ShowMediaPicker())
+			r.Post("/", router.handlers.Admin().HandleUploadMedia())
+			r.Group(func(r chi.Router) {
+				r.Use(custommw.RequireRole(models.CanEditAllPosts))
+				r.Post("/{id}", router.handlers.Admin().HandleUpdateMedia())
+				r.Delete("/{id}", router.handlers.Admin().HandleDeleteMedia())
+			})
+		})
+
+		// Trash
+		r.Route("/trash", func(r chi.Router) {
+			r.Get("/", router.handlers.Admin().ShowTrash())
+			r.Post("/{id}/restore", router.handlers.Admin().HandleRestorePost())
+			r.With(custommw.RequireRole(models.CanEditAllPosts)).Delete("/{id}", router.handlers.Admin().HandlePurgePost())
+		})
+
+		// Account
+		r.Get("/account/password", router.handlers.Auth().ShowChangePassword())
+		r.Post("/account/password", router.handlers.Auth().HandleChangePassword())
+		r.Get("/account/profile", router.handlers.Auth().ShowProfile())
+		r.Post("/account/profile", router.handlers.Auth().HandleUpdateProfile())
+		r.Get("/account/2fa", router.handlers.Auth().ShowTwoFactor())
+		r.Post("/account/2fa/enable", router.handlers.Auth().HandleEnableTwoFactor())
+		r.Post("/account/2fa/disable", router.handlers.Auth().HandleDisableTwoFactor())
+		r.Post("/account/2fa/recovery-codes", router.handlers.Auth().HandleRegenerateRecoveryCodes())
+
+		// Accounts, admins only
+		r.Route("/users", func(r chi.Router) {
+			r.Use(custommw.RequireRole(models.CanManageSite))
+			r.Get("/", router.handlers.Auth().ShowUsers())
+			r.Post("/", router.handlers.Auth().HandleCreateUser())
+			r.Post("/{id}/role", router.handlers.Auth().HandleChangeRole())
+			r.Post("/{id}/2fa/reset", router.handlers.Auth().HandleResetTwoFactor())
+		})
+
+		// Database backups, admins only
+		r.Route("/backups", func(r chi.Router) {
+			r.Use(custommw.RequireRole(models.CanManageSite))
+			r.Get("/", router.handlers.Admin().ShowBackups())
+			r.Post("/", router.handlers.Admin().HandleCreateBackup())
+			r.Get("/{name}", router.handlers.Admin().HandleDownloadBackup())
+		})
 	})
 }
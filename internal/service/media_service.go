@@ -0,0 +1,348 @@
+// internal/service/media_service.go
+package service
+
+import (
+	"blog-portfolio/internal/imaging"
+	"blog-portfolio/internal/models"
+	"blog-portfolio/internal/repository"
+	"blog-portfolio/internal/storage"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	libwebp "github.com/chai2010/webp"
+	_ "golang.org/x/image/webp" // Register the WebP decoder with image.Decode
+)
+
+var (
+	// ErrUnsupportedMediaType is returned when an upload isn't an image we can process
+	ErrUnsupportedMediaType = errors.New("unsupported file type: upload a JPEG, PNG, GIF or WebP image")
+
+	// ErrImageTooLarge is returned for images whose dimensions exceed the configured limit
+	ErrImageTooLarge = errors.New("image dimensions are too large")
+)
+
+// jpegQuality is used for resized variants of JPEG uploads and their WebP copies
+const jpegQuality = 85
+
+// maxWebPDimension is the largest width or height libwebp encodes
+const maxWebPDimension = 16383
+
+// mediaExtensions maps the image types we accept to the extension they're stored with
+var mediaExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// mediaSizes are the widths of the generated variants
+var mediaSizes = []struct {
+	name  string
+	width int
+}{
+	{models.MediaThumbnail, 320},
+	{models.MediaContent, 960},
+	{models.MediaContent2x, 1920},
+}
+
+// MediaService stores uploaded images and generates resized variants of them. Each
+// size is encoded in the upload's own family (JPEG for photos, PNG otherwise) and as
+// WebP, lossy for photos and lossless otherwise, and the WebP copy is kept only when it
+// is the smaller of the two.
+type MediaService struct {
+	repo      *repository.MediaRepository
+	store     storage.Storage
+	maxPixels int64 // Largest width × height decoded
+}
+
+func NewMediaService(repo *repository.MediaRepository, store storage.Storage, maxPixels int64) *MediaService {
+	return &MediaService{
+		repo:      repo,
+		store:     store,
+		maxPixels: maxPixels,
+	}
+}
+
+// Upload stores an image and its variants. The type is sniffed from the content, so
+// the client's file name and content type are never trusted.
+func (s *MediaService) Upload(ctx context.Context, filename string, data []byte, alt string) (*models.Media, error) {
+	contentType := http.DetectContentType(data)
+	ext, ok := mediaExtensions[contentType]
+	if !ok {
+		return nil, ErrUnsupportedMediaType
+	}
+
+	// Check the declared dimensions before decoding allocates memory for them
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedMediaType
+	}
+	if pixels := int64(cfg.Width) * int64(cfg.Height); pixels > s.maxPixels {
+		return nil, fmt.Errorf("%w: %d×%d is over %d megapixels", ErrImageTooLarge, cfg.Width, cfg.Height, s.maxPixels/1000/1000)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedMediaType
+	}
+
+	id, err := newMediaID()
+	if err != nil {
+		return nil, err
+	}
+	base := path.Join(time.Now().UTC().Format("2006/01"), id)
+
+	bounds := img.Bounds()
+	media := &models.Media{
+		Filename:    filepath.Base(filename),
+		Path:        base + ext,
+		ContentType: contentType,
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+		Size:        int64(len(data)),
+		AltText:     strings.TrimSpace(alt),
+	}
+
+	files := map[string][]byte{media.Path: data}
+	for _, size := range mediaSizes {
+		// Never upscale; pages fall back to the original for sizes it doesn't reach
+		if media.Width <= size.width {
+			continue
+		}
+		// Resizing would drop every frame but the first, so animated GIFs only get a thumbnail
+		if contentType == "image/gif" && size.name != models.MediaThumbnail {
+			continue
+		}
+
+		variants, err := encodeVariants(imaging.ResizeToWidth(img, size.width), contentType)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range variants {
+			v.variant.Name = size.name
+			v.variant.Path = base + "-" + size.name + "." + v.variant.Format
+			files[v.variant.Path] = v.data
+			media.Variants = append(media.Variants, v.variant)
+		}
+	}
+
+	written := make([]string, 0, len(files))
+	for name, content := range files {
+		if err := s.store.Put(ctx, name, bytes.NewReader(content), int64(len(content)), mime.TypeByExtension(path.Ext(name))); err != nil {
+			s.removeFiles(ctx, written)
+			return nil, err
+		}
+		written = append(written, name)
+	}
+
+	if err := s.repo.Create(ctx, media); err != nil {
+		s.removeFiles(ctx, written)
+		return nil, err
+	}
+
+	return media, nil
+}
+
+// GetMedia retrieves a media item by ID
+func (s *MediaService) GetMedia(ctx context.Context, id int64) (*models.Media, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// ListMedia returns media items newest first, filtered by query when it isn't empty
+func (s *MediaService) ListMedia(ctx context.Context, query string, limit, offset int) ([]*models.Media, error) {
+	return s.repo.List(ctx, query, limit, offset)
+}
+
+// UpdateAltText changes a media item's alt text
+func (s *MediaService) UpdateAltText(ctx context.Context, id int64, alt string) error {
+	return s.repo.UpdateAltText(ctx, id, strings.TrimSpace(alt))
+}
+
+// DeleteMedia removes a media item along with its files. Posts that still reference
+// it will show a broken image.
+func (s *MediaService) DeleteMedia(ctx context.Context, id int64) error {
+	media, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if media == nil {
+		return sql.ErrNoRows
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.removeFiles(ctx, mediaFiles(media))
+	return nil
+}
+
+// Open opens a stored media file for serving
+func (s *MediaService) Open(ctx context.Context, name string) (storage.File, error) {
+	return s.store.Open(ctx, name)
+}
+
+// FileURL returns where a stored media file can be fetched from directly, or "" when
+// it has to be served by the app
+func (s *MediaService) FileURL(ctx context.Context, name string) (string, error) {
+	return s.store.URL(ctx, name)
+}
+
+// CopyFiles copies every stored file to dst, skipping files dst already has, so an
+// interrupted copy can simply be run again. It returns how many files were copied and
+// skipped.
+func (s *MediaService) CopyFiles(ctx context.Context, dst storage.Storage) (copied, skipped int, err error) {
+	items, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, media := range items {
+		for _, name := range mediaFiles(media) {
+			exists, err := dst.Exists(ctx, name)
+			if err != nil {
+				return copied, skipped, err
+			}
+			if exists {
+				skipped++
+				continue
+			}
+
+			if err := copyFile(ctx, s.store, dst, name); err != nil {
+				return copied, skipped, fmt.Errorf("copying %s: %w", name, err)
+			}
+			copied++
+		}
+	}
+
+	return copied, skipped, nil
+}
+
+// ListFiles returns the names of every stored file, originals and variants
+func (s *MediaService) ListFiles(ctx context.Context) ([]string, error) {
+	items, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, media := range items {
+		names = append(names, mediaFiles(media)...)
+	}
+	return names, nil
+}
+
+func (s *MediaService) removeFiles(ctx context.Context, names []string) {
+	for _, name := range names {
+		s.store.Delete(ctx, name)
+	}
+}
+
+// mediaFiles returns the names of the original and every variant of a media item
+func mediaFiles(media *models.Media) []string {
+	names := []string{media.Path}
+	for _, v := range media.Variants {
+		names = append(names, v.Path)
+	}
+	return names
+}
+
+func copyFile(ctx context.Context, src, dst storage.Storage, name string) error {
+	file, err := src.Open(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return dst.Put(ctx, name, file, file.Size(), mime.TypeByExtension(path.Ext(name)))
+}
+
+type encodedVariant struct {
+	variant models.MediaVariant
+	data    []byte
+}
+
+// encodeVariants encodes a resized image in the upload's family and, when it is
+// smaller, as WebP with libwebp. Photos get lossy WebP at the JPEG quality, since a
+// lossless copy of one is never smaller than the JPEG
+func encodeVariants(img image.Image, contentType string) ([]encodedVariant, error) {
+	var buf bytes.Buffer
+	format := "png"
+	switch contentType {
+	case "image/jpeg":
+		format = "jpeg"
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+	case "image/gif":
+		format = "gif"
+		if err := gif.Encode(&buf, img, nil); err != nil {
+			return nil, err
+		}
+	default:
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+	}
+
+	bounds := img.Bounds()
+	variants := []encodedVariant{{
+		variant: models.MediaVariant{
+			Format: format,
+			Width:  bounds.Dx(),
+			Height: bounds.Dy(),
+			Size:   int64(buf.Len()),
+		},
+		data: buf.Bytes(),
+	}}
+
+	// libwebp can't describe larger images, so they only get the upload's own format
+	if bounds.Dx() > maxWebPDimension || bounds.Dy() > maxWebPDimension {
+		return variants, nil
+	}
+	var webp bytes.Buffer
+	options := &libwebp.Options{Lossless: true}
+	if format == "jpeg" {
+		options = &libwebp.Options{Quality: jpegQuality}
+	}
+	if err := libwebp.Encode(&webp, img, options); err != nil {
+		return nil, err
+	}
+	if webp.Len() < buf.Len() {
+		variants = append(variants, encodedVariant{
+			variant: models.MediaVariant{
+				Format: "webp",
+				Width:  bounds.Dx(),
+				Height: bounds.Dy(),
+				Size:   int64(webp.Len()),
+			},
+			data: webp.Bytes(),
+		})
+	}
+
+	return variants, nil
+}
+
+// newMediaID returns a random name for a stored upload
+func newMediaID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -4,45 +4,74 @@ package service
 import (
 	"blog-portfolio/internal/models"
 	"blog-portfolio/internal/repository"
+	"blog-portfolio/internal/slug"
 	"blog-portfolio/internal/utils"
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"time"
+	"unicode"
 )
 
+// ErrRevisionNotFound is returned when a revision doesn't exist or belongs to another post
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// ErrSlugTaken is returned when a requested slug already belongs to another post
+var ErrSlugTaken = errors.New("slug is already used by another post")
+
+// ErrScheduleInPast is returned when a post is scheduled for a time that has already passed
+var ErrScheduleInPast = errors.New("scheduled publish time must be in the future")
+
 type PostService struct {
-	repo *repository.PostRepository
+	repo       repository.PostRepository
+	tocOptions utils.TOCOptions
+
+	// scheduleChanged wakes the background publisher when a schedule is added or moved
+	scheduleChanged chan struct{}
 }
 
-func NewPostService(repo *repository.PostRepository) *PostService {
-	return &PostService{repo: repo}
+func NewPostService(repo repository.PostRepository, tocOptions utils.TOCOptions) *PostService {
+	return &PostService{
+		repo:            repo,
+		tocOptions:      tocOptions,
+		scheduleChanged: make(chan struct{}, 1),
+	}
 }
 
-// CreatePost creates a new blog post
-func (s *PostService) CreatePost(ctx context.Context, post *models.Post, tagIds []int64) error {
-	// Generate slug if not provided
-	if post.Slug == "" {
-		post.Slug = generateSlug(post.Title)
+// CreatePost creates a new blog post. info names who is creating it.
+func (s *PostService) CreatePost(ctx context.Context, post *models.Post, tagIds []int64, info models.RevisionInfo) error {
+	if err := s.resolveSlug(ctx, post); err != nil {
+		return err
 	}
 
-	// Set published time if post is published
-	if post.Published && post.PublishedAt == nil {
-		now := time.Now()
-		post.PublishedAt = &now
+	if err := preparePublishState(post); err != nil {
+		return err
 	}
 
-	return s.repo.CreatePost(ctx, post)
+	// Render once on save so reads can serve the stored HTML
+	post.Render(s.tocOptions)
+
+	info.Note = "Created"
+	if err := s.repo.CreatePost(ctx, post, info); err != nil {
+		return err
+	}
+
+	s.notifyScheduleChanged(post)
+	return nil
 }
 
 // GetPost retrieves a post by its slug
 func (s *PostService) GetPost(ctx context.Context, slug string) (*models.Post, error) {
 	post, err := s.repo.GetPost(ctx, slug)
-	if err != nil {
+	if err != nil || post == nil {
 		return nil, err
 	}
 
-	// Calculate reading time
-	post.ReadingTime = utils.CalculateReadingTime(post.Content)
+	// Posts saved before rendered content was stored are rendered on the fly
+	if post.ContentHTML == "" {
+		post.Render(s.tocOptions)
+	}
 
 	return post, nil
 }
@@ -52,47 +81,227 @@ func (s *PostService) ListPosts(ctx context.Context, filter models.PostFilter) (
 	return s.repo.ListPosts(ctx, filter)
 }
 
-// UpdatePost updates an existing post
-func (s *PostService) UpdatePost(ctx context.Context, post *models.Post, tagIds []int64) error {
-	// Update published time if post is being published
-	if post.Published && post.PublishedAt == nil {
-		now := time.Now()
-		post.PublishedAt = &now
+// UpdatePost updates an existing post, recording info with the revision it creates
+func (s *PostService) UpdatePost(ctx context.Context, post *models.Post, tagIds []int64, info models.RevisionInfo) error {
+	if err := s.resolveSlug(ctx, post); err != nil {
+		return err
 	}
 
-	return s.repo.UpdatePost(ctx, post)
+	if err := preparePublishState(post); err != nil {
+		return err
+	}
+
+	post.Render(s.tocOptions)
+
+	if err := s.repo.UpdatePost(ctx, post, info); err != nil {
+		return err
+	}
+
+	s.notifyScheduleChanged(post)
+	return nil
 }
 
-// DeletePost deletes a post by ID
-func (s *PostService) DeletePost(ctx context.Context, id int64) error {
-	return s.repo.DeletePost(ctx, id)
+// resolveSlug normalizes the post's requested slug and makes sure no other post uses it.
+// Without a requested slug one is generated from the title, adding a numeric suffix
+// ("-2", "-3", ...) until it is unique.
+func (s *PostService) resolveSlug(ctx context.Context, post *models.Post) error {
+	if strings.TrimSpace(post.Slug) == "" {
+		unique, err := s.uniqueSlug(ctx, slug.Make(post.Title), post.ID)
+		if err != nil {
+			return err
+		}
+		post.Slug = unique
+		return nil
+	}
+
+	requested := slug.Make(post.Slug)
+
+	taken, err := s.repo.SlugTaken(ctx, requested, post.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return fmt.Errorf("%w: %q", ErrSlugTaken, requested)
+	}
+
+	post.Slug = requested
+	return nil
+}
+
+// uniqueSlug returns base, or base with the first numeric suffix not used by another post
+func (s *PostService) uniqueSlug(ctx context.Context, base string, postID int64) (string, error) {
+	candidate := base
+	for n := 2; ; n++ {
+		taken, err := s.repo.SlugTaken(ctx, candidate, postID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// GetRedirectSlug returns the current slug for a post's former slug, or an empty string
+func (s *PostService) GetRedirectSlug(ctx context.Context, slug string) (string, error) {
+	return s.repo.GetRedirectSlug(ctx, slug)
+}
+
+// preparePublishState sets the publish time for published posts and validates schedules.
+// A published post is never scheduled.
+func preparePublishState(post *models.Post) error {
+	if post.Published {
+		post.ScheduledAt = nil
+		if post.PublishedAt == nil {
+			now := time.Now()
+			post.PublishedAt = &now
+		}
+		return nil
+	}
+
+	if post.ScheduledAt != nil && !post.ScheduledAt.After(time.Now()) {
+		return ErrScheduleInPast
+	}
+	return nil
+}
+
+// PublishDue publishes every scheduled post whose time has come
+func (s *PostService) PublishDue(ctx context.Context) (int64, error) {
+	return s.repo.PublishScheduled(ctx, time.Now())
+}
+
+// NextScheduledAt returns the earliest pending publish time, or nil if nothing is scheduled
+func (s *PostService) NextScheduledAt(ctx context.Context) (*time.Time, error) {
+	return s.repo.NextScheduledAt(ctx)
 }
 
-// Helper function to generate URL-friendly slugs
-func generateSlug(title string) string {
-	// Convert to lowercase
-	slug := strings.ToLower(title)
+// ScheduleChanged signals whenever a post's schedule is saved
+func (s *PostService) ScheduleChanged() <-chan struct{} {
+	return s.scheduleChanged
+}
 
-	// Replace spaces with hyphens
-	slug = strings.ReplaceAll(slug, " ", "-")
+func (s *PostService) notifyScheduleChanged(post *models.Post) {
+	if post.ScheduledAt == nil {
+		return
+	}
+	select {
+	case s.scheduleChanged <- struct{}{}:
+	default:
+		// A wake-up is already pending
+	}
+}
 
-	// Remove special characters
-	slug = strings.Map(func(r rune) rune {
-		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
-			return r
+// ListRevisions returns a post's revision history, newest first
+func (s *PostService) ListRevisions(ctx context.Context, postID int64) ([]models.PostRevision, error) {
+	return s.repo.ListRevisions(ctx, postID)
+}
+
+// GetRevision retrieves a revision by its ID
+func (s *PostService) GetRevision(ctx context.Context, id int64) (*models.PostRevision, error) {
+	return s.repo.GetRevision(ctx, id)
+}
+
+// RestoreRevision copies a revision's content back onto its post.
+// The restore is saved like any other edit, so it creates a new revision itself.
+func (s *PostService) RestoreRevision(ctx context.Context, postID, revisionID int64, info models.RevisionInfo) error {
+	rev, err := s.repo.GetRevision(ctx, revisionID)
+	if err != nil {
+		return err
+	}
+	if rev == nil || rev.PostID != postID {
+		return ErrRevisionNotFound
+	}
+
+	post, err := s.repo.GetPostByID(ctx, postID)
+	if err != nil {
+		return err
+	}
+	if post == nil {
+		return ErrRevisionNotFound
+	}
+
+	post.Title = rev.Title
+	post.Content = rev.Content
+	post.Description = rev.Description
+	post.CoverImage = rev.CoverImage
+	post.Render(s.tocOptions)
+
+	info.Note = fmt.Sprintf("Restored revision #%d", rev.ID)
+	return s.repo.UpdatePost(ctx, post, info)
+}
+
+// RerenderPosts re-renders and stores the HTML of every post, including those in the
+// trash so they come back up to date, returning how many were updated. Run it after
+// changing the Markdown renderer configuration.
+func (s *PostService) RerenderPosts(ctx context.Context) (int, error) {
+	count := 0
+	for _, filter := range []models.PostFilter{{}, {Trashed: true}} {
+		posts, err := s.repo.ListPosts(ctx, filter)
+		if err != nil {
+			return count, err
 		}
-		return -1
-	}, slug)
 
-	// Remove multiple consecutive hyphens
-	for strings.Contains(slug, "--") {
-		slug = strings.ReplaceAll(slug, "--", "-")
+		for _, post := range posts {
+			post.Render(s.tocOptions)
+			if err := s.repo.UpdateRenderedContent(ctx, post); err != nil {
+				return count, err
+			}
+			count++
+		}
 	}
 
-	// Trim hyphens from start and end
-	slug = strings.Trim(slug, "-")
+	return count, nil
+}
+
+// RebuildSearchIndex repopulates the search index from the stored posts, returning how
+// many were indexed. Run it if search results have drifted from the posts.
+func (s *PostService) RebuildSearchIndex(ctx context.Context) (int64, error) {
+	return s.repo.RebuildSearchIndex(ctx)
+}
+
+// Search returns published posts matching a reader's query, best matches first
+func (s *PostService) Search(ctx context.Context, query string, limit, offset int) ([]models.SearchResult, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	return s.repo.Search(ctx, terms, limit, offset)
+}
+
+// searchTerms splits free text into the words to search for. Only letters and digits
+// are kept, so reader input can't inject query syntax into any database's search.
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// DeletePost moves a post to the trash, hiding it from every listing
+func (s *PostService) DeletePost(ctx context.Context, id int64) error {
+	return s.repo.DeletePost(ctx, id)
+}
+
+// ListTrash returns trashed posts, most recently deleted first. A non-zero authorID
+// limits them to that author's.
+func (s *PostService) ListTrash(ctx context.Context, authorID int64) ([]*models.Post, error) {
+	return s.repo.ListPosts(ctx, models.PostFilter{Trashed: true, AuthorID: authorID})
+}
 
-	return slug
+// RestorePost moves a post out of the trash
+func (s *PostService) RestorePost(ctx context.Context, id int64) error {
+	return s.repo.RestorePost(ctx, id)
+}
+
+// PurgePost permanently deletes a trashed post
+func (s *PostService) PurgePost(ctx context.Context, id int64) error {
+	return s.repo.PurgePost(ctx, id)
+}
+
+// PurgeExpiredTrash permanently deletes posts that have been in the trash longer than retention
+func (s *PostService) PurgeExpiredTrash(ctx context.Context, retention time.Duration) (int64, error) {
+	return s.repo.PurgeTrashedBefore(ctx, time.Now().Add(-retention))
 }
 
 // internal/service/post_service.go
@@ -110,5 +319,3 @@ func (s *PostService) ListTags(ctx context.Context) ([]models.Tag, error) {
 // CreatePost creates a new post with tags
 
 // UpdatePost updates an existing post and its tags
-
-// generateSlug creates a URL-friendly version of the title
@@ -0,0 +1,152 @@
+// internal/service/preview_service.go
+package service
+
+import (
+	"blog-portfolio/internal/models"
+	"blog-portfolio/internal/repository"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxPreviewTTL is the longest a preview link may stay valid
+const MaxPreviewTTL = 30 * 24 * time.Hour
+
+var (
+	// ErrInvalidPreviewTTL is returned when a preview link's lifetime is out of range
+	ErrInvalidPreviewTTL = errors.New("preview link duration must be between one minute and 30 days")
+
+	// ErrInvalidPreviewToken is returned for tokens that are malformed, forged, expired or revoked
+	ErrInvalidPreviewToken = errors.New("invalid preview token")
+)
+
+// PreviewService issues and verifies signed share links for unpublished posts.
+// A token is "<link id>.<expiry>.<signature>", where the HMAC signature also covers the
+// post ID, so a token only ever opens the post it was issued for. Links are stored so
+// they can be revoked before they expire.
+type PreviewService struct {
+	repo    *repository.PreviewRepository
+	secret  []byte
+	baseURL string
+}
+
+func NewPreviewService(repo *repository.PreviewRepository, secret, baseURL string) *PreviewService {
+	return &PreviewService{
+		repo:    repo,
+		secret:  []byte(secret),
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// CreateLink issues a preview link for post that expires after ttl
+func (s *PreviewService) CreateLink(ctx context.Context, post *models.Post, ttl time.Duration) (*models.PreviewLink, error) {
+	if ttl < time.Minute || ttl > MaxPreviewTTL {
+		return nil, ErrInvalidPreviewTTL
+	}
+
+	// Expired links can never be used again, so tidy them up as new ones are made
+	now := time.Now()
+	if err := s.repo.DeleteExpired(ctx, now); err != nil {
+		return nil, err
+	}
+
+	id, err := newLinkID()
+	if err != nil {
+		return nil, err
+	}
+
+	link := &models.PreviewLink{
+		ID:     id,
+		PostID: post.ID,
+		// Whole seconds, since that's what the token carries
+		ExpiresAt: now.Add(ttl).Truncate(time.Second),
+	}
+	if err := s.repo.CreateLink(ctx, link); err != nil {
+		return nil, err
+	}
+
+	link.URL = s.linkURL(post, link)
+	return link, nil
+}
+
+// ListLinks returns the post's unexpired preview links with their share URLs
+func (s *PreviewService) ListLinks(ctx context.Context, post *models.Post) ([]models.PreviewLink, error) {
+	links, err := s.repo.ListLinks(ctx, post.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for i := range links {
+		links[i].URL = s.linkURL(post, &links[i])
+	}
+	return links, nil
+}
+
+// RevokeLink deletes a post's preview link so its token stops working
+func (s *PreviewService) RevokeLink(ctx context.Context, postID int64, id string) error {
+	return s.repo.DeleteLink(ctx, postID, id)
+}
+
+// Verify checks that token grants access to post and returns the link it belongs to
+func (s *PreviewService) Verify(ctx context.Context, post *models.Post, token string) (*models.PreviewLink, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidPreviewToken
+	}
+	id, expiryStr, signature := parts[0], parts[1], parts[2]
+
+	expiry, err := strconv.ParseInt(expiryStr, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidPreviewToken
+	}
+
+	// Check the signature before touching the database so forged tokens are cheap to reject
+	expected := s.sign(id, post.ID, expiry)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, ErrInvalidPreviewToken
+	}
+	if !time.Now().Before(time.Unix(expiry, 0)) {
+		return nil, ErrInvalidPreviewToken
+	}
+
+	link, err := s.repo.GetLink(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil || link.PostID != post.ID {
+		// Revoked
+		return nil, ErrInvalidPreviewToken
+	}
+
+	return link, nil
+}
+
+// linkURL builds the absolute share URL for a link
+func (s *PreviewService) linkURL(post *models.Post, link *models.PreviewLink) string {
+	expiry := link.ExpiresAt.Unix()
+	token := fmt.Sprintf("%s.%d.%s", link.ID, expiry, s.sign(link.ID, link.PostID, expiry))
+	return s.baseURL + "/blog/" + url.PathEscape(post.Slug) + "?preview=" + url.QueryEscape(token)
+}
+
+// sign returns the token signature for a link
+func (s *PreviewService) sign(id string, postID, expiry int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "%s.%d.%d", id, postID, expiry)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// newLinkID returns a random, URL-safe link ID
+func newLinkID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
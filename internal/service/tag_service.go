@@ -8,10 +8,10 @@ import (
 )
 
 type TagService struct {
-	repo *repository.TagRepository
+	repo repository.TagRepository
 }
 
-func NewTagService(repo *repository.TagRepository) *TagService {
+func NewTagService(repo repository.TagRepository) *TagService {
 	return &TagService{repo: repo}
 }
 
@@ -48,3 +48,11 @@ func (s *TagService) ListTags(ctx context.Context) ([]models.Tag, error) {
 func (s *TagService) GetTagByID(ctx context.Context, id int64) (*models.Tag, error) {
 	return s.repo.GetTagByID(ctx, id)
 }
+
+func (s *TagService) GetTagBySlug(ctx context.Context, slug string) (*models.Tag, error) {
+	return s.repo.GetTagBySlug(ctx, slug)
+}
+
+func (s *TagService) ListPublishedTags(ctx context.Context) ([]models.Tag, error) {
+	return s.repo.ListPublishedTags(ctx)
+}
@@ -0,0 +1,373 @@
+// internal/service/two_factor.go
+package service
+
+import (
+	"blog-portfolio/internal/models"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// RecoveryCodeCount is how many recovery codes are issued at a time
+	RecoveryCodeCount = 10
+
+	// recoveryCodeLength is the number of characters in a recovery code, not counting
+	// the dash shown in the middle
+	recoveryCodeLength = 10
+
+	// recoveryCodeAlphabet leaves out characters that are easily confused, like 0 and o
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+	// totpPeriod and totpSkew are the RFC 6238 defaults authenticator apps expect: a
+	// new code every 30 seconds, with the previous and next code also accepted to
+	// allow for clock drift
+	totpPeriod = 30
+	totpSkew   = 1
+
+	// maxSecondFactorFailures wrong codes in a row lock out the second login step for
+	// secondFactorLockout, so the six digits can't be guessed by brute force
+	maxSecondFactorFailures = 5
+	secondFactorLockout     = 15 * time.Minute
+
+	// totpEnrollmentTTL is how long a secret offered for setup waits to be confirmed
+	totpEnrollmentTTL = 30 * time.Minute
+)
+
+var (
+	// ErrInvalidCode is returned for authentication and recovery codes that don't match
+	ErrInvalidCode = errors.New("that code isn't valid")
+
+	// ErrTooManyAttempts is returned while the second login step is locked out
+	ErrTooManyAttempts = errors.New("too many wrong codes; wait a few minutes and sign in again")
+
+	// ErrTwoFactorEnabled is returned when setting up two-factor login twice
+	ErrTwoFactorEnabled = errors.New("two-factor authentication is already on")
+
+	// ErrTwoFactorDisabled is returned when changing two-factor login that isn't set up
+	ErrTwoFactorDisabled = errors.New("two-factor authentication is off")
+
+	// ErrEnrollmentExpired is returned when confirming a setup whose secret has expired
+	ErrEnrollmentExpired = errors.New("the two-factor setup expired; scan the new QR code")
+)
+
+// pendingTOTPKeys holds the secret each user is setting up, so the one confirmed is
+// always one the server generated for them rather than one sent by the browser
+type pendingTOTPKeys struct {
+	mu   sync.Mutex
+	keys map[int64]pendingTOTPKey
+}
+
+type pendingTOTPKey struct {
+	key     *otp.Key
+	expires time.Time
+}
+
+// get returns the unexpired key pending for id, or nil
+func (p *pendingTOTPKeys) get(id int64, now time.Time) *otp.Key {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pending, ok := p.keys[id]
+	if !ok || now.After(pending.expires) {
+		delete(p.keys, id)
+		return nil
+	}
+	return pending.key
+}
+
+func (p *pendingTOTPKeys) put(id int64, key *otp.Key, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.keys == nil {
+		p.keys = make(map[int64]pendingTOTPKey)
+	}
+	p.keys[id] = pendingTOTPKey{key: key, expires: now.Add(totpEnrollmentTTL)}
+}
+
+func (p *pendingTOTPKeys) remove(id int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.keys, id)
+}
+
+// secondFactorFailures counts wrong second-factor codes per account
+type secondFactorFailures struct {
+	mu       sync.Mutex
+	failures map[int64]failureCount
+}
+
+type failureCount struct {
+	count int
+	last  time.Time
+}
+
+// locked reports whether id has used up its attempts
+func (f *secondFactorFailures) locked(id int64, now time.Time) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	failure, ok := f.failures[id]
+	if ok && now.Sub(failure.last) > secondFactorLockout {
+		delete(f.failures, id)
+		return false
+	}
+	return failure.count >= maxSecondFactorFailures
+}
+
+func (f *secondFactorFailures) fail(id int64, now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures == nil {
+		f.failures = make(map[int64]failureCount)
+	}
+	failure := f.failures[id]
+	f.failures[id] = failureCount{count: failure.count + 1, last: now}
+}
+
+func (f *secondFactorFailures) reset(id int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.failures, id)
+}
+
+// PendingTOTPKey returns the secret a user is setting up for their authenticator app,
+// generating one if they have none pending. It is kept in memory only, until
+// EnableTOTP confirms the app produces matching codes or totpEnrollmentTTL passes.
+func (s *UserService) PendingTOTPKey(user *models.User, issuer string) (*otp.Key, error) {
+	now := time.Now()
+	if key := s.pendingTOTP.get(user.ID, now); key != nil {
+		return key, nil
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: user.Username,
+		Period:      totpPeriod,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.pendingTOTP.put(user.ID, key, now)
+	return key, nil
+}
+
+// EnableTOTP turns on two-factor login for a user with their pending secret, after
+// checking their password and that code shows their authenticator app has the secret.
+// It returns the user's recovery codes, which are only ever shown now.
+func (s *UserService) EnableTOTP(ctx context.Context, id int64, password, code string) ([]string, error) {
+	user, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorEnabled
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	key := s.pendingTOTP.get(id, now)
+	if key == nil {
+		return nil, ErrEnrollmentExpired
+	}
+	step, ok := matchTOTP(key.Secret(), code, now)
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.EnableTOTP(ctx, id, key.Secret(), step, hashes); err != nil {
+		return nil, err
+	}
+	s.pendingTOTP.remove(id)
+	return codes, nil
+}
+
+// DisableTOTP turns off two-factor login for a user after checking their password
+func (s *UserService) DisableTOTP(ctx context.Context, id int64, password string) error {
+	if _, err := s.checkTwoFactorChange(ctx, id, password); err != nil {
+		return err
+	}
+	return s.repo.DisableTOTP(ctx, id)
+}
+
+// ResetTOTP turns off two-factor login for the named user without their password,
+// for when they have lost both their authenticator and their recovery codes
+func (s *UserService) ResetTOTP(ctx context.Context, username string) error {
+	user, err := s.repo.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if !user.TwoFactorEnabled() {
+		return ErrTwoFactorDisabled
+	}
+
+	s.failures.reset(user.ID)
+	return s.repo.DisableTOTP(ctx, user.ID)
+}
+
+// RegenerateRecoveryCodes replaces a user's recovery codes after checking their
+// password, returning the new ones
+func (s *UserService) RegenerateRecoveryCodes(ctx context.Context, id int64, password string) ([]string, error) {
+	if _, err := s.checkTwoFactorChange(ctx, id, password); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, id, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// CountRecoveryCodes returns how many of a user's recovery codes are left
+func (s *UserService) CountRecoveryCodes(ctx context.Context, id int64) (int, error) {
+	return s.repo.CountRecoveryCodes(ctx, id)
+}
+
+// checkTwoFactorChange loads a user with two-factor login on and checks their password
+func (s *UserService) checkTwoFactorChange(ctx context.Context, id int64, password string) (*models.User, error) {
+	user, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if !user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorDisabled
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// VerifySecondFactor completes a login that passed Authenticate, accepting either a
+// code from the user's authenticator app or one of their unused recovery codes. Each
+// code works only once.
+func (s *UserService) VerifySecondFactor(ctx context.Context, id int64, code string) (*models.User, error) {
+	now := time.Now()
+	if s.failures.locked(id, now) {
+		return nil, ErrTooManyAttempts
+	}
+
+	user, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if !user.TwoFactorEnabled() {
+		// Reset since the password was checked, so the password alone is enough again
+		return user, nil
+	}
+
+	ok := false
+	if step, matched := matchTOTP(user.TOTPSecret, code, now); matched {
+		ok, err = s.repo.UseTOTPStep(ctx, id, step)
+	} else if recovery := normalizeRecoveryCode(code); len(recovery) == recoveryCodeLength {
+		ok, err = s.repo.UseRecoveryCode(ctx, id, hashRecoveryCode(recovery))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		s.failures.fail(id, now)
+		return nil, ErrInvalidCode
+	}
+
+	s.failures.reset(id)
+	return user, nil
+}
+
+// matchTOTP checks code against secret at t and the neighbouring time steps,
+// returning the step that matched
+func matchTOTP(secret, code string, t time.Time) (int64, bool) {
+	code = strings.Join(strings.Fields(code), "")
+	if len(code) != int(otp.DigitsSix) {
+		return 0, false
+	}
+
+	opts := totp.ValidateOpts{
+		Period:    totpPeriod,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	}
+	step := t.Unix() / totpPeriod
+	for i := -totpSkew; i <= totpSkew; i++ {
+		at := time.Unix((step+int64(i))*totpPeriod, 0)
+		want, err := totp.GenerateCodeCustom(secret, at, opts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1 {
+			return step + int64(i), true
+		}
+	}
+	return 0, false
+}
+
+// newRecoveryCodes generates a set of recovery codes, returning them formatted for
+// the user along with the hashes to store
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	hashes := make([]string, RecoveryCodeCount)
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	for i := range codes {
+		code := make([]byte, recoveryCodeLength)
+		for j := range code {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return nil, nil, err
+			}
+			code[j] = recoveryCodeAlphabet[n.Int64()]
+		}
+		half := recoveryCodeLength / 2
+		codes[i] = string(code[:half]) + "-" + string(code[half:])
+		hashes[i] = hashRecoveryCode(string(code))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode drops the dash and spaces people type or paste with a code
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+}
+
+// hashRecoveryCode hashes a normalized recovery code for storage. The codes are long
+// and random, so a fast hash is enough.
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,317 @@
+// internal/service/user_service.go
+package service
+
+import (
+	"blog-portfolio/internal/models"
+	"blog-portfolio/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// MinPasswordLength is the shortest password accepted for an account
+	MinPasswordLength = 10
+
+	// maxPasswordLength is the most bcrypt will hash; longer passwords would be truncated
+	maxPasswordLength = 72
+)
+
+var (
+	// ErrInvalidCredentials is returned when a username or password doesn't match
+	ErrInvalidCredentials = errors.New("invalid username or password")
+
+	// ErrInvalidUsername is returned for usernames that are empty, too long or use other characters
+	ErrInvalidUsername = errors.New("username must be 1 to 64 letters, digits, dots, dashes or underscores")
+
+	// ErrUsernameTaken is returned when another account already has the username
+	ErrUsernameTaken = errors.New("username is already taken")
+
+	// ErrWeakPassword is returned for passwords outside the accepted length
+	ErrWeakPassword = fmt.Errorf("password must be between %d and %d characters", MinPasswordLength, maxPasswordLength)
+
+	// ErrSetupComplete is returned by first-run setup once an account exists
+	ErrSetupComplete = errors.New("an admin account already exists")
+
+	// ErrUserNotFound is returned when changing an account that doesn't exist
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrInvalidRole is returned for roles other than those in models.Roles
+	ErrInvalidRole = errors.New("role must be admin, editor, author or contributor")
+
+	// ErrLastAdmin is returned when a change would leave no admin to manage the site
+	ErrLastAdmin = errors.New("the last admin can't be given another role")
+
+	// ErrInvalidProfile is returned for author profiles with overlong fields or an unusable avatar URL
+	ErrInvalidProfile = fmt.Errorf("display name must be at most %d characters, bio at most %d, and the avatar an http(s) or site-relative URL", maxDisplayNameLength, maxBioLength)
+)
+
+// Limits on the public author profile
+const (
+	maxDisplayNameLength = 100
+	maxBioLength         = 1000
+)
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// UserService manages admin accounts and checks their passwords
+type UserService struct {
+	repo  repository.UserRepository
+	setup sync.Mutex // Serializes first-run setup and role changes, so neither races to leave no admin
+
+	failures    secondFactorFailures // Wrong second-factor codes, to lock out guessing
+	pendingTOTP pendingTOTPKeys      // Secrets offered for two-factor setup, not yet confirmed
+
+	// dummyHash is compared against when a username doesn't exist, so a failed login
+	// takes as long whether or not the account exists
+	dummyHash     []byte
+	dummyHashOnce sync.Once
+}
+
+func NewUserService(repo repository.UserRepository) *UserService {
+	return &UserService{repo: repo}
+}
+
+// NeedsSetup reports whether no account exists yet, so the first admin must be created
+func (s *UserService) NeedsSetup(ctx context.Context) (bool, error) {
+	count, err := s.repo.CountUsers(ctx)
+	if err != nil {
+		return false, err
+	}
+	return count == 0, nil
+}
+
+// CreateFirstAdmin creates the initial admin account, failing with ErrSetupComplete
+// if any account already exists. Posts without an author, such as those written
+// before accounts existed, are credited to it.
+func (s *UserService) CreateFirstAdmin(ctx context.Context, username, password string) (*models.User, error) {
+	s.setup.Lock()
+	defer s.setup.Unlock()
+
+	needed, err := s.NeedsSetup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !needed {
+		return nil, ErrSetupComplete
+	}
+
+	user, err := s.newUser(ctx, username, password, models.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateFirstUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// CreateUser creates an account with the given role
+func (s *UserService) CreateUser(ctx context.Context, username, password, role string) (*models.User, error) {
+	user, err := s.newUser(ctx, username, password, role)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// newUser validates a new account and hashes its password, ready to be stored
+func (s *UserService) newUser(ctx context.Context, username, password, role string) (*models.User, error) {
+	if !usernamePattern.MatchString(username) {
+		return nil, ErrInvalidUsername
+	}
+	if !models.ValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	existing, err := s.repo.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrUsernameTaken
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.User{
+		Username:     username,
+		PasswordHash: hash,
+		Role:         role,
+	}, nil
+}
+
+// Authenticate returns the account matching username and password, or
+// ErrInvalidCredentials. bcrypt compares hashes in constant time, and a missing
+// account is compared against a dummy hash so its absence can't be timed.
+func (s *UserService) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	user, err := s.repo.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		bcrypt.CompareHashAndPassword(s.getDummyHash(), []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// GetUser returns the account with the given ID, or nil if it doesn't exist
+func (s *UserService) GetUser(ctx context.Context, id int64) (*models.User, error) {
+	return s.repo.GetUserByID(ctx, id)
+}
+
+// GetAuthor returns the public profile of the account with the given username for
+// its author page, or nil if it doesn't exist
+func (s *UserService) GetAuthor(ctx context.Context, username string) (*models.Author, error) {
+	user, err := s.repo.GetUserByUsername(ctx, username)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	return user.Profile(), nil
+}
+
+// ListUsers returns every account by username
+func (s *UserService) ListUsers(ctx context.Context) ([]models.User, error) {
+	return s.repo.ListUsers(ctx)
+}
+
+// UpdateProfile replaces the public display name, bio and avatar of an account
+func (s *UserService) UpdateProfile(ctx context.Context, id int64, displayName, bio, avatar string) (*models.User, error) {
+	user, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	user.DisplayName = strings.TrimSpace(displayName)
+	user.Bio = strings.TrimSpace(bio)
+	user.Avatar = strings.TrimSpace(avatar)
+	if utf8.RuneCountInString(user.DisplayName) > maxDisplayNameLength ||
+		utf8.RuneCountInString(user.Bio) > maxBioLength ||
+		!validAvatarURL(user.Avatar) {
+		return nil, ErrInvalidProfile
+	}
+
+	if err := s.repo.UpdateProfile(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// validAvatarURL accepts no avatar, an http(s) URL or a path on this site such as an
+// uploaded image, so a profile can't smuggle in javascript: or data: URLs
+func validAvatarURL(avatar string) bool {
+	return avatar == "" ||
+		strings.HasPrefix(avatar, "https://") ||
+		strings.HasPrefix(avatar, "http://") ||
+		(strings.HasPrefix(avatar, "/") && !strings.HasPrefix(avatar, "//"))
+}
+
+// ChangeRole gives an account another role. The last admin can't be demoted, so
+// someone can always manage accounts.
+func (s *UserService) ChangeRole(ctx context.Context, id int64, role string) error {
+	if !models.ValidRole(role) {
+		return ErrInvalidRole
+	}
+
+	s.setup.Lock()
+	defer s.setup.Unlock()
+
+	users, err := s.repo.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+	var user *models.User
+	admins := 0
+	for i := range users {
+		if users[i].ID == id {
+			user = &users[i]
+		}
+		if users[i].Role == models.RoleAdmin {
+			admins++
+		}
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if user.Role == models.RoleAdmin && role != models.RoleAdmin && admins == 1 {
+		return ErrLastAdmin
+	}
+
+	return s.repo.UpdateRole(ctx, id, role)
+}
+
+// ChangePassword replaces a user's password after checking their current one
+func (s *UserService) ChangePassword(ctx context.Context, id int64, current, password string) error {
+	user, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(current)); err != nil {
+		return ErrInvalidCredentials
+	}
+
+	return s.setPassword(ctx, user.ID, password)
+}
+
+// ResetPassword replaces the password of the named user without checking the old one
+func (s *UserService) ResetPassword(ctx context.Context, username, password string) error {
+	user, err := s.repo.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	return s.setPassword(ctx, user.ID, password)
+}
+
+func (s *UserService) setPassword(ctx context.Context, id int64, password string) error {
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	return s.repo.UpdatePassword(ctx, id, hash)
+}
+
+// getDummyHash hashes a throwaway password at the same cost as real ones, once
+func (s *UserService) getDummyHash() []byte {
+	s.dummyHashOnce.Do(func() {
+		s.dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+	})
+	return s.dummyHash
+}
+
+// hashPassword checks a new password's length and returns its bcrypt hash
+func hashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength || len(password) > maxPasswordLength {
+		return "", ErrWeakPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
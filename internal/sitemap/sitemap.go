@@ -0,0 +1,104 @@
+// internal/sitemap/sitemap.go
+package sitemap
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// MaxURLs is the most URLs the sitemap protocol allows in a single sitemap file
+const MaxURLs = 50000
+
+const namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// URL is a page listed in a sitemap
+type URL struct {
+	Loc     string     // Absolute URL
+	LastMod *time.Time // When the page last changed, if known
+}
+
+// Sitemap is an entry in a sitemap index
+type Sitemap struct {
+	Loc     string
+	LastMod *time.Time
+}
+
+type urlSet struct {
+	XMLName xml.Name   `xml:"urlset"`
+	XMLNS   string     `xml:"xmlns,attr"`
+	URLs    []entryXML `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name   `xml:"sitemapindex"`
+	XMLNS    string     `xml:"xmlns,attr"`
+	Sitemaps []entryXML `xml:"sitemap"`
+}
+
+type entryXML struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// WriteURLSet writes a sitemap listing urls
+func WriteURLSet(w io.Writer, urls []URL) error {
+	doc := urlSet{XMLNS: namespace, URLs: []entryXML{}}
+	for _, u := range urls {
+		doc.URLs = append(doc.URLs, entry(u.Loc, u.LastMod))
+	}
+	return write(w, doc)
+}
+
+// WriteIndex writes a sitemap index pointing at sitemaps
+func WriteIndex(w io.Writer, sitemaps []Sitemap) error {
+	doc := sitemapIndex{XMLNS: namespace}
+	for _, s := range sitemaps {
+		doc.Sitemaps = append(doc.Sitemaps, entry(s.Loc, s.LastMod))
+	}
+	return write(w, doc)
+}
+
+// Split divides urls into chunks of at most size URLs, one per sitemap file
+func Split(urls []URL, size int) [][]URL {
+	if size <= 0 || size > MaxURLs {
+		size = MaxURLs
+	}
+	var chunks [][]URL
+	for len(urls) > size {
+		chunks = append(chunks, urls[:size])
+		urls = urls[size:]
+	}
+	return append(chunks, urls)
+}
+
+// LatestMod returns the most recent LastMod among urls, or nil if none has one
+func LatestMod(urls []URL) *time.Time {
+	var latest *time.Time
+	for _, u := range urls {
+		if u.LastMod != nil && (latest == nil || u.LastMod.After(*latest)) {
+			latest = u.LastMod
+		}
+	}
+	return latest
+}
+
+func entry(loc string, lastMod *time.Time) entryXML {
+	e := entryXML{Loc: loc}
+	if lastMod != nil {
+		e.LastMod = lastMod.UTC().Format(time.RFC3339)
+	}
+	return e
+}
+
+func write(w io.Writer, doc interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
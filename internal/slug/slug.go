@@ -0,0 +1,80 @@
+// internal/slug/slug.go
+package slug
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/gosimple/unidecode"
+)
+
+// MaxLength is the default maximum length of a generated slug
+const MaxLength = 80
+
+// Make creates a URL-friendly slug from s, capped at MaxLength
+func Make(s string) string {
+	return MakeLength(s, MaxLength)
+}
+
+// MakeLength creates a URL-friendly slug from s. Accented Latin, Cyrillic, Greek and other
+// scripts are transliterated to ASCII, and the result is cut at a word boundary so it is no
+// longer than maxLength. Text with nothing to transliterate falls back to a short ID derived
+// from s, so the slug is never empty and the same input always gives the same slug.
+func MakeLength(s string, maxLength int) string {
+	words := words(unidecode.Unidecode(s))
+
+	var b strings.Builder
+	for _, word := range words {
+		if b.Len() == 0 {
+			// A single word longer than the limit has no boundary to cut at
+			if len(word) > maxLength {
+				word = word[:maxLength]
+			}
+			b.WriteString(word)
+			continue
+		}
+		if b.Len()+1+len(word) > maxLength {
+			break
+		}
+		b.WriteByte('-')
+		b.WriteString(word)
+	}
+
+	if b.Len() == 0 {
+		return shortID(s)
+	}
+	return b.String()
+}
+
+// words splits transliterated text into lowercase runs of ASCII letters and digits.
+// Apostrophes are dropped rather than splitting words, so "don't" becomes "dont".
+func words(s string) []string {
+	var result []string
+	var word strings.Builder
+
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			word.WriteRune(r)
+		case r == '\'':
+		default:
+			if word.Len() > 0 {
+				result = append(result, word.String())
+				word.Reset()
+			}
+		}
+	}
+	if word.Len() > 0 {
+		result = append(result, word.String())
+	}
+
+	return result
+}
+
+// shortID returns a short hexadecimal ID derived from s
+func shortID(s string) string {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
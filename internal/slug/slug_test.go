@@ -0,0 +1,78 @@
+// internal/slug/slug_test.go
+package slug
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain", "Hello, World!", "hello-world"},
+		{"accented Latin", "Café über Straße", "cafe-uber-strasse"},
+		{"Cyrillic", "Привет, мир", "privet-mir"},
+		{"Greek", "Καλημέρα κόσμε", "kalemera-kosme"},
+		{"apostrophes", "Don't Stop Believin'", "dont-stop-believin"},
+		{"curly apostrophe", "It’s here", "its-here"},
+		{"digits", "Go 1.23 released", "go-1-23-released"},
+		{"surrounding separators", "  --Trim me--  ", "trim-me"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Make(tt.in); got != tt.want {
+				t.Errorf("Make(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMakeFallback checks that text with nothing to transliterate still gets a slug,
+// the same one every time
+func TestMakeFallback(t *testing.T) {
+	hexID := regexp.MustCompile(`^[0-9a-f]{8}$`)
+	for _, in := range []string{"", "!!!", "🎉🎉"} {
+		got := Make(in)
+		if !hexID.MatchString(got) {
+			t.Errorf("Make(%q) = %q, want a short hexadecimal ID", in, got)
+		}
+		if again := Make(in); again != got {
+			t.Errorf("Make(%q) gave %q, then %q", in, got, again)
+		}
+	}
+	if Make("!!!") == Make("???") {
+		t.Error("different inputs got the same fallback ID")
+	}
+}
+
+func TestMakeLength(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        string
+		maxLength int
+		want      string
+	}{
+		{"fits", "one two three", 13, "one-two-three"},
+		{"cut at a word boundary", "one two three four", 13, "one-two-three"},
+		{"never mid-word", "one two three", 12, "one-two"},
+		{"single overlong word", "supercalifragilistic", 10, "supercalif"},
+		{"overlong word after the first", "ab supercalifragilistic", 10, "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MakeLength(tt.in, tt.maxLength)
+			if got != tt.want {
+				t.Errorf("MakeLength(%q, %d) = %q, want %q", tt.in, tt.maxLength, got, tt.want)
+			}
+			if len(got) > tt.maxLength {
+				t.Errorf("MakeLength(%q, %d) = %q is longer than the limit", tt.in, tt.maxLength, got)
+			}
+		})
+	}
+
+	if got := Make(strings.Repeat("word ", 40)); len(got) > MaxLength || strings.HasSuffix(got, "-") {
+		t.Errorf("Make of a long title = %q, want at most %d characters ending in a word", got, MaxLength)
+	}
+}
@@ -0,0 +1,120 @@
+// internal/storage/local.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Local stores files in a directory on disk. The app serves them itself.
+type Local struct {
+	dir string
+}
+
+func NewLocal(dir string) *Local {
+	return &Local{dir: dir}
+}
+
+// Put writes the file through a temporary file so readers never see a partial write
+func (l *Local) Put(ctx context.Context, name string, r io.Reader, size int64, contentType string) error {
+	path, err := l.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (l *Local) Open(ctx context.Context, name string) (File, error) {
+	path, err := l.path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		f.Close()
+		return nil, ErrNotExist
+	}
+
+	return &localFile{File: f, info: info}, nil
+}
+
+func (l *Local) Delete(ctx context.Context, name string) error {
+	path, err := l.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (l *Local) Exists(ctx context.Context, name string) (bool, error) {
+	path, err := l.path(name)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return !info.IsDir(), nil
+}
+
+// URL returns "" since local files are only reachable through the app's /media/ route
+func (l *Local) URL(ctx context.Context, name string) (string, error) {
+	return "", nil
+}
+
+// path maps a storage name to a path inside the directory
+func (l *Local) path(name string) (string, error) {
+	if !validName(name) {
+		return "", ErrNotExist
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(name)), nil
+}
+
+type localFile struct {
+	*os.File
+	info fs.FileInfo
+}
+
+func (f *localFile) Size() int64        { return f.info.Size() }
+func (f *localFile) ModTime() time.Time { return f.info.ModTime() }
@@ -0,0 +1,132 @@
+// internal/storage/s3.go
+package storage
+
+import (
+	"blog-portfolio/internal/config"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3 stores files in a bucket on any S3-compatible service, such as AWS S3 or MinIO.
+// Files are fetched straight from the bucket, through PublicURL when the bucket (or a
+// CDN in front of it) is public and through presigned URLs otherwise.
+type S3 struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string
+	urlExpiry time.Duration
+}
+
+func NewS3(cfg config.S3Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("s3 storage needs an endpoint and a bucket")
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &S3{
+		client:    client,
+		bucket:    cfg.Bucket,
+		publicURL: strings.TrimSuffix(cfg.PublicURL, "/"),
+		urlExpiry: cfg.URLExpiry(),
+	}, nil
+}
+
+// Put uploads the file. Stored names are never reused, so they're marked as cacheable forever.
+func (s *S3) Put(ctx context.Context, name string, r io.Reader, size int64, contentType string) error {
+	if !validName(name) {
+		return ErrNotExist
+	}
+	_, err := s.client.PutObject(ctx, s.bucket, name, r, size, minio.PutObjectOptions{
+		ContentType:  contentType,
+		CacheControl: "public, max-age=31536000, immutable",
+	})
+	return err
+}
+
+func (s *S3) Open(ctx context.Context, name string) (File, error) {
+	if !validName(name) {
+		return nil, ErrNotExist
+	}
+
+	obj, err := s.client.GetObject(ctx, s.bucket, name, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s3Error(err)
+	}
+	// GetObject is lazy, so this is where a missing object shows up
+	info, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, s3Error(err)
+	}
+
+	return &s3File{Object: obj, info: info}, nil
+}
+
+func (s *S3) Delete(ctx context.Context, name string) error {
+	if !validName(name) {
+		return ErrNotExist
+	}
+	return s.client.RemoveObject(ctx, s.bucket, name, minio.RemoveObjectOptions{})
+}
+
+func (s *S3) Exists(ctx context.Context, name string) (bool, error) {
+	if !validName(name) {
+		return false, nil
+	}
+	_, err := s.client.StatObject(ctx, s.bucket, name, minio.StatObjectOptions{})
+	if err != nil {
+		if err := s3Error(err); errors.Is(err, ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// URL returns the file's public URL, or a presigned one when no public URL is configured
+func (s *S3) URL(ctx context.Context, name string) (string, error) {
+	if !validName(name) {
+		return "", ErrNotExist
+	}
+	if s.publicURL != "" {
+		return s.publicURL + "/" + (&url.URL{Path: name}).EscapedPath(), nil
+	}
+
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, name, s.urlExpiry, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// s3Error maps missing object errors to ErrNotExist
+func s3Error(err error) error {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NotFound":
+		return ErrNotExist
+	}
+	return err
+}
+
+type s3File struct {
+	*minio.Object
+	info minio.ObjectInfo
+}
+
+func (f *s3File) Size() int64        { return f.info.Size }
+func (f *s3File) ModTime() time.Time { return f.info.LastModified }
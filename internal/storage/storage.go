@@ -0,0 +1,73 @@
+// internal/storage/storage.go
+package storage
+
+import (
+	"blog-portfolio/internal/config"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+)
+
+// Backend names accepted in the media storage configuration
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// ErrNotExist is returned when a file isn't in storage
+var ErrNotExist = fs.ErrNotExist
+
+// Storage holds uploaded media files. Names are slash separated paths relative to the
+// root of the store, such as "2024/05/abc123.jpg".
+type Storage interface {
+	// Put stores size bytes from r under name, replacing any existing file
+	Put(ctx context.Context, name string, r io.Reader, size int64, contentType string) error
+
+	// Open opens a stored file for reading
+	Open(ctx context.Context, name string) (File, error)
+
+	// Delete removes a file. Deleting a file that doesn't exist is not an error.
+	Delete(ctx context.Context, name string) error
+
+	// Exists reports whether a file is stored under name
+	Exists(ctx context.Context, name string) (bool, error)
+
+	// URL returns where the file can be fetched from directly, signed when the backend
+	// isn't public. Backends that are only reachable through the app return "".
+	URL(ctx context.Context, name string) (string, error)
+}
+
+// File is an open stored file
+type File interface {
+	io.ReadSeekCloser
+
+	// Size returns the file's length in bytes
+	Size() int64
+
+	// ModTime returns when the file was last written
+	ModTime() time.Time
+}
+
+// New returns the storage backend selected in the media configuration
+func New(cfg config.MediaConfig) (Storage, error) {
+	return NewBackend(cfg.Storage, cfg)
+}
+
+// NewBackend returns the named storage backend, configured from cfg
+func NewBackend(name string, cfg config.MediaConfig) (Storage, error) {
+	switch name {
+	case BackendLocal, "":
+		return NewLocal(cfg.Dir), nil
+	case BackendS3:
+		return NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown media storage backend %q", name)
+	}
+}
+
+// validName reports whether name is a clean relative path that can't escape the store
+func validName(name string) bool {
+	return name != "." && fs.ValidPath(name)
+}
@@ -0,0 +1,174 @@
+// internal/storage/storage_test.go
+package storage_test
+
+import (
+	"blog-portfolio/internal/config"
+	"blog-portfolio/internal/storage"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// invalidNames are names that are not clean relative paths and must never reach the
+// backing store
+var invalidNames = []string{
+	"",
+	".",
+	"..",
+	"../escape.jpg",
+	"2024/../../escape.jpg",
+	"/etc/passwd",
+	"2024//05/a.jpg",
+	"./a.jpg",
+	"2024/05/",
+	"2024/./05/a.jpg",
+}
+
+// testBackend checks the behaviour every backend shares
+func testBackend(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	const name = "2024/05/abc123.txt"
+	const content = "hello, storage"
+
+	t.Run("put and open", func(t *testing.T) {
+		if err := s.Put(ctx, name, strings.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		f, err := s.Open(ctx, name)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		defer f.Close()
+
+		data, err := io.ReadAll(f)
+		if err != nil {
+			t.Fatalf("reading: %v", err)
+		}
+		if string(data) != content {
+			t.Errorf("read %q, want %q", data, content)
+		}
+		if f.Size() != int64(len(content)) {
+			t.Errorf("Size = %d, want %d", f.Size(), len(content))
+		}
+		if f.ModTime().IsZero() {
+			t.Error("ModTime is zero")
+		}
+
+		if _, err := f.Seek(7, io.SeekStart); err != nil {
+			t.Fatalf("Seek: %v", err)
+		}
+		rest, err := io.ReadAll(f)
+		if err != nil {
+			t.Fatalf("reading after seek: %v", err)
+		}
+		if string(rest) != content[7:] {
+			t.Errorf("read %q after seeking, want %q", rest, content[7:])
+		}
+	})
+
+	t.Run("put replaces", func(t *testing.T) {
+		const replaced = "replaced"
+		if err := s.Put(ctx, name, strings.NewReader(replaced), int64(len(replaced)), "text/plain"); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		f, err := s.Open(ctx, name)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		defer f.Close()
+		if data, _ := io.ReadAll(f); string(data) != replaced {
+			t.Errorf("read %q, want %q", data, replaced)
+		}
+	})
+
+	t.Run("exists", func(t *testing.T) {
+		if ok, err := s.Exists(ctx, name); err != nil || !ok {
+			t.Errorf("Exists(%q) = %v, %v, want true", name, ok, err)
+		}
+		if ok, err := s.Exists(ctx, "2024/05/missing.txt"); err != nil || ok {
+			t.Errorf("Exists of a missing file = %v, %v, want false", ok, err)
+		}
+		if ok, _ := s.Exists(ctx, "2024/05"); ok {
+			t.Error("Exists reports a directory as a file")
+		}
+	})
+
+	t.Run("open missing", func(t *testing.T) {
+		if _, err := s.Open(ctx, "2024/05/missing.txt"); !errors.Is(err, storage.ErrNotExist) {
+			t.Errorf("Open of a missing file = %v, want ErrNotExist", err)
+		}
+	})
+
+	t.Run("invalid names", func(t *testing.T) {
+		for _, bad := range invalidNames {
+			if err := s.Put(ctx, bad, strings.NewReader("x"), 1, "text/plain"); !errors.Is(err, storage.ErrNotExist) {
+				t.Errorf("Put(%q) = %v, want ErrNotExist", bad, err)
+			}
+			if _, err := s.Open(ctx, bad); !errors.Is(err, storage.ErrNotExist) {
+				t.Errorf("Open(%q) = %v, want ErrNotExist", bad, err)
+			}
+			if ok, _ := s.Exists(ctx, bad); ok {
+				t.Errorf("Exists(%q) = true", bad)
+			}
+			if _, err := s.URL(ctx, bad); !errors.Is(err, storage.ErrNotExist) && err != nil {
+				t.Errorf("URL(%q) = %v, want ErrNotExist", bad, err)
+			}
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := s.Delete(ctx, name); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if ok, err := s.Exists(ctx, name); err != nil || ok {
+			t.Errorf("Exists after Delete = %v, %v, want false", ok, err)
+		}
+		if err := s.Delete(ctx, name); err != nil {
+			t.Errorf("deleting a missing file: %v", err)
+		}
+	})
+}
+
+func TestLocal(t *testing.T) {
+	testBackend(t, storage.NewLocal(t.TempDir()))
+}
+
+// TestLocalStaysInDirectory checks that invalid names write nothing outside the
+// storage directory
+func TestLocalStaysInDirectory(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "media")
+	s := storage.NewLocal(dir)
+
+	for _, bad := range invalidNames {
+		s.Put(context.Background(), bad, strings.NewReader("x"), 1, "text/plain")
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != "media" {
+			t.Errorf("found %q outside the storage directory", e.Name())
+		}
+	}
+}
+
+func TestLocalURL(t *testing.T) {
+	s := storage.NewLocal(t.TempDir())
+	url, err := s.URL(context.Background(), "2024/05/abc123.jpg")
+	if err != nil || url != "" {
+		t.Errorf("URL = %q, %v, want no direct URL", url, err)
+	}
+}
+
+func TestNewBackend(t *testing.T) {
+	if _, err := storage.NewBackend("ftp", config.MediaConfig{}); err == nil {
+		t.Error("NewBackend accepted an unknown backend")
+	}
+}
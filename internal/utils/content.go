@@ -1,17 +1,12 @@
 package utils
 
 import (
+	"html"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 )
 
-type TOCEntry struct {
-	ID       string
-	Level    int
-	Title    string
-	Children []TOCEntry
-}
-
 // CalculateReadingTime estimates reading time in minutes
 func CalculateReadingTime(content string) int {
 	words := len(strings.Fields(content))
@@ -23,30 +18,25 @@ func CalculateReadingTime(content string) int {
 	return minutes
 }
 
-// GenerateTableOfContents parses markdown content for headings
-func GenerateTableOfContents(content string) []TOCEntry {
-	var toc []TOCEntry
-
-	// Regex for markdown headings
-	headingRegex := regexp.MustCompile(`(?m)^(#{1,6})\s+(.+)$`)
-	matches := headingRegex.FindAllStringSubmatch(content, -1)
-
-	for _, match := range matches {
-		level := len(match[1]) // number of # symbols
-		title := strings.TrimSpace(match[2])
-		id := strings.ToLower(strings.ReplaceAll(title, " ", "-"))
-
-		// Remove any special characters from ID
-		id = regexp.MustCompile(`[^a-z0-9-]`).ReplaceAllString(id, "")
-
-		entry := TOCEntry{
-			ID:    id,
-			Level: level,
-			Title: title,
-		}
+var (
+	blockTagPattern = regexp.MustCompile(`(?is)</?(?:p|div|br|hr|li|ul|ol|h[1-6]|pre|blockquote|table|tr|td|th|figure|figcaption)\b[^>]*>`)
+	htmlTagPattern  = regexp.MustCompile(`(?s)<[^>]*>`)
+)
 
-		toc = append(toc, entry)
+// Excerpt returns the start of rendered HTML as plain text, cut at a word boundary
+// after at most maxLen characters
+func Excerpt(contentHTML string, maxLen int) string {
+	// Block tags become spaces so words in adjacent blocks don't run together
+	text := blockTagPattern.ReplaceAllString(contentHTML, " ")
+	text = html.UnescapeString(htmlTagPattern.ReplaceAllString(text, ""))
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= maxLen {
+		return text
 	}
 
-	return toc
+	cut := []rune(text)[:maxLen]
+	if i := strings.LastIndexByte(string(cut), ' '); i > 0 {
+		return string(cut)[:i] + "…"
+	}
+	return string(cut) + "…"
 }
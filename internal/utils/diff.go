@@ -0,0 +1,122 @@
+// internal/utils/diff.go
+package utils
+
+import "strings"
+
+// DiffOp is the kind of change a diff line represents
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = "equal"
+	DiffInsert DiffOp = "insert"
+	DiffDelete DiffOp = "delete"
+)
+
+// DiffLine is one line of a line-level diff. OldLine and NewLine are 1-based
+// line numbers, zero when the line doesn't exist on that side.
+type DiffLine struct {
+	Op      DiffOp
+	Text    string
+	OldLine int
+	NewLine int
+}
+
+// DiffLines computes a line-level diff that turns oldText into newText
+func DiffLines(oldText, newText string) []DiffLine {
+	a := splitLines(oldText)
+	b := splitLines(newText)
+
+	// Common prefix and suffix are cheap to match and keep the LCS table small
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var diff []DiffLine
+	for i := 0; i < prefix; i++ {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: a[i], OldLine: i + 1, NewLine: i + 1})
+	}
+
+	diff = append(diff, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], prefix, prefix)...)
+
+	for i := 0; i < suffix; i++ {
+		oldIdx := len(a) - suffix + i
+		newIdx := len(b) - suffix + i
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: a[oldIdx], OldLine: oldIdx + 1, NewLine: newIdx + 1})
+	}
+
+	return diff
+}
+
+// maxDiffCells caps the size of the LCS table, which takes memory and time in
+// proportion to the product of the changed regions' lengths
+const maxDiffCells = 1 << 20
+
+// diffMiddle diffs the changed region using a longest common subsequence table.
+// Regions too large for the table are shown as deleted and then inserted whole.
+func diffMiddle(a, b []string, oldOffset, newOffset int) []DiffLine {
+	if len(a) > 0 && len(b) > maxDiffCells/len(a) {
+		diff := make([]DiffLine, 0, len(a)+len(b))
+		for i := range a {
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: a[i], OldLine: oldOffset + i + 1})
+		}
+		for j := range b {
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: b[j], NewLine: newOffset + j + 1})
+		}
+		return diff
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff []DiffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, DiffLine{Op: DiffEqual, Text: a[i], OldLine: oldOffset + i + 1, NewLine: newOffset + j + 1})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: a[i], OldLine: oldOffset + i + 1})
+			i++
+		default:
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: b[j], NewLine: newOffset + j + 1})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, DiffLine{Op: DiffDelete, Text: a[i], OldLine: oldOffset + i + 1})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, DiffLine{Op: DiffInsert, Text: b[j], NewLine: newOffset + j + 1})
+	}
+
+	return diff
+}
+
+// splitLines splits text into lines, ignoring a trailing newline
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,54 @@
+// internal/utils/diff_test.go
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	diff := DiffLines("a\nb\nc\nd\n", "a\nc\nx\nd\n")
+	want := []DiffLine{
+		{Op: DiffEqual, Text: "a", OldLine: 1, NewLine: 1},
+		{Op: DiffDelete, Text: "b", OldLine: 2},
+		{Op: DiffEqual, Text: "c", OldLine: 3, NewLine: 2},
+		{Op: DiffInsert, Text: "x", NewLine: 3},
+		{Op: DiffEqual, Text: "d", OldLine: 4, NewLine: 4},
+	}
+	if fmt.Sprint(diff) != fmt.Sprint(want) {
+		t.Errorf("DiffLines = %v, want %v", diff, want)
+	}
+}
+
+// TestDiffLinesLargeChange checks that a change too large for the LCS table is shown
+// as a deletion followed by an insertion instead of allocating the table
+func TestDiffLinesLargeChange(t *testing.T) {
+	var oldText, newText strings.Builder
+	oldText.WriteString("same\n")
+	newText.WriteString("same\n")
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&oldText, "old %d\n", i)
+		fmt.Fprintf(&newText, "new %d\n", i)
+	}
+
+	diff := DiffLines(oldText.String(), newText.String())
+	if len(diff) != 4001 {
+		t.Fatalf("got %d lines, want 4001", len(diff))
+	}
+	if diff[0].Op != DiffEqual {
+		t.Errorf("first line is %v, want the common prefix", diff[0].Op)
+	}
+	for i, line := range diff[1:] {
+		want := DiffDelete
+		if i >= 2000 {
+			want = DiffInsert
+		}
+		if line.Op != want {
+			t.Fatalf("line %d is %v, want %v", i+1, line.Op, want)
+		}
+	}
+	if last := diff[len(diff)-1]; last.NewLine != 2001 || last.Text != "new 1999" {
+		t.Errorf("last line = %+v", last)
+	}
+}
@@ -0,0 +1,140 @@
+// internal/utils/highlight.go
+package utils
+
+import (
+	"bytes"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// HighlightStyle is the chroma style used to generate the code block stylesheet
+const HighlightStyle = "github-dark"
+
+// codeBlockInfo holds the options parsed from a fenced code block's info string
+type codeBlockInfo struct {
+	Language    string
+	LineNumbers bool
+	Highlight   [][2]int
+}
+
+// highlightCSS is generated when the package loads. The style is compiled in, so a
+// failure is a bug and stops the program instead of serving an empty stylesheet.
+var highlightCSS = mustWriteHighlightCSS()
+
+// HighlightCSS returns the stylesheet for the classes emitted by the code highlighter
+func HighlightCSS() string {
+	return highlightCSS
+}
+
+func mustWriteHighlightCSS() string {
+	var buf bytes.Buffer
+	if err := newCodeFormatter(codeBlockInfo{}).WriteCSS(&buf, styles.Get(HighlightStyle)); err != nil {
+		panic("generating the code highlighting stylesheet: " + err.Error())
+	}
+	return buf.String()
+}
+
+// renderHookCodeBlock highlights fenced code blocks, leaving every other node to the default renderer
+func renderHookCodeBlock(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
+	block, ok := node.(*ast.CodeBlock)
+	if !ok || !block.IsFenced {
+		return ast.GoToNext, false
+	}
+
+	info := parseCodeBlockInfo(string(block.Info))
+	if err := highlightCode(w, string(block.Literal), info); err != nil {
+		// Fall back to a plain code block so the content is never lost
+		io.WriteString(w, "<pre><code>"+html.EscapeString(string(block.Literal))+"</code></pre>\n")
+	}
+
+	return ast.GoToNext, true
+}
+
+// highlightCode tokenizes source with the lexer for the block's language and writes class-based HTML
+func highlightCode(w io.Writer, source string, info codeBlockInfo) error {
+	lexer := lexers.Get(info.Language)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	iterator, err := lexer.Tokenise(nil, source)
+	if err != nil {
+		return err
+	}
+
+	return newCodeFormatter(info).Format(w, styles.Get(HighlightStyle), iterator)
+}
+
+func newCodeFormatter(info codeBlockInfo) *chromahtml.Formatter {
+	return chromahtml.New(
+		chromahtml.WithClasses(true),
+		chromahtml.TabWidth(4),
+		chromahtml.WithLineNumbers(info.LineNumbers),
+		chromahtml.HighlightLines(info.Highlight),
+	)
+}
+
+// parseCodeBlockInfo parses info strings such as "go", "go {3-5}" or "go linenos {1,4-6}"
+func parseCodeBlockInfo(info string) codeBlockInfo {
+	var result codeBlockInfo
+
+	info = strings.TrimSpace(info)
+	attrs := ""
+	if i := strings.Index(info, "{"); i >= 0 {
+		attrs = strings.NewReplacer("{", " ", "}", " ").Replace(info[i:])
+		info = info[:i]
+	}
+
+	fields := strings.Fields(info)
+	if len(fields) > 0 {
+		result.Language = strings.ToLower(fields[0])
+		fields = fields[1:]
+	}
+
+	// Attributes may follow the language directly or sit inside braces
+	fields = append(fields, strings.FieldsFunc(attrs, func(r rune) bool {
+		return r == ',' || r == ' '
+	})...)
+
+	for _, field := range fields {
+		switch field {
+		case "linenos", "numbers", "showLineNumbers":
+			result.LineNumbers = true
+			continue
+		}
+
+		if r, ok := parseLineRange(field); ok {
+			result.Highlight = append(result.Highlight, r)
+		}
+	}
+
+	return result
+}
+
+// parseLineRange parses "7" or "3-5" into an inclusive line range
+func parseLineRange(s string) ([2]int, bool) {
+	start, end, isRange := strings.Cut(s, "-")
+
+	from, err := strconv.Atoi(start)
+	if err != nil || from < 1 {
+		return [2]int{}, false
+	}
+	if !isRange {
+		return [2]int{from, from}, true
+	}
+
+	to, err := strconv.Atoi(end)
+	if err != nil || to < from {
+		return [2]int{}, false
+	}
+	return [2]int{from, to}, true
+}
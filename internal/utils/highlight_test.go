@@ -0,0 +1,84 @@
+// internal/utils/highlight_test.go
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHighlightCSS(t *testing.T) {
+	css := HighlightCSS()
+	if !strings.Contains(css, ".chroma") {
+		t.Fatalf("stylesheet has no .chroma rules:\n%s", css)
+	}
+}
+
+func TestParseCodeBlockInfo(t *testing.T) {
+	tests := []struct {
+		info string
+		want codeBlockInfo
+	}{
+		{"", codeBlockInfo{}},
+		{"Go", codeBlockInfo{Language: "go"}},
+		{"go {3-5}", codeBlockInfo{Language: "go", Highlight: [][2]int{{3, 5}}}},
+		{"go{3-5}", codeBlockInfo{Language: "go", Highlight: [][2]int{{3, 5}}}},
+		{"go {1,4-6}", codeBlockInfo{Language: "go", Highlight: [][2]int{{1, 1}, {4, 6}}}},
+		{"go linenos", codeBlockInfo{Language: "go", LineNumbers: true}},
+		{"go linenos {1,4-6}", codeBlockInfo{Language: "go", LineNumbers: true, Highlight: [][2]int{{1, 1}, {4, 6}}}},
+		{"go {showLineNumbers 2}", codeBlockInfo{Language: "go", LineNumbers: true, Highlight: [][2]int{{2, 2}}}},
+		{"{3-5}", codeBlockInfo{Highlight: [][2]int{{3, 5}}}},
+		{"go {5-3}", codeBlockInfo{Language: "go"}},
+		{"go {0,x,2-}", codeBlockInfo{Language: "go"}},
+	}
+	for _, tt := range tests {
+		got := parseCodeBlockInfo(tt.info)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("parseCodeBlockInfo(%q) = %+v, want %+v", tt.info, got, tt.want)
+		}
+	}
+}
+
+func TestParseLineRange(t *testing.T) {
+	tests := []struct {
+		in   string
+		want [2]int
+		ok   bool
+	}{
+		{"7", [2]int{7, 7}, true},
+		{"3-5", [2]int{3, 5}, true},
+		{"4-4", [2]int{4, 4}, true},
+		{"5-3", [2]int{}, false},
+		{"0", [2]int{}, false},
+		{"-2", [2]int{}, false},
+		{"2-", [2]int{}, false},
+		{"linenos", [2]int{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseLineRange(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseLineRange(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestRenderHighlightedCode checks that a fenced block's highlighted lines and line
+// numbers reach the rendered HTML used by post pages and previews
+func TestRenderHighlightedCode(t *testing.T) {
+	html := RenderMarkdown("Code", "```go linenos {2}\npackage main\n\nfunc main() {}\n```\n")
+
+	for _, want := range []string{
+		`<pre class="chroma">`,
+		`<span class="line hl"><span class="ln">2</span>`,
+		`<span class="ln">1</span>`,
+		`<span class="ln">3</span>`,
+		`<span class="kn">package</span>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("rendered code is missing %q:\n%s", want, html)
+		}
+	}
+	if n := strings.Count(html, `class="line hl"`); n != 1 {
+		t.Errorf("%d lines are highlighted, want 1:\n%s", n, html)
+	}
+}
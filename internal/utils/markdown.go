@@ -0,0 +1,49 @@
+// internal/utils/markdown.go
+package utils
+
+import (
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// RenderedContent holds the HTML and table of contents produced from one parse of a post
+type RenderedContent struct {
+	HTML string
+	TOC  []TOCEntry
+}
+
+// RenderMarkdown converts post Markdown into HTML with highlighted code blocks
+func RenderMarkdown(title, content string) string {
+	return Render(title, content, DefaultTOCOptions).HTML
+}
+
+// Render parses the Markdown once and builds both the HTML and the table of contents from
+// the same AST, so TOC links always match the rendered heading IDs
+func Render(title, content string, tocOpts TOCOptions) RenderedContent {
+	doc := parseMarkdown(content)
+	uniqueHeadingIDs(doc)
+
+	// Create HTML renderer with options
+	opts := html.RendererOptions{
+		Flags:          html.CommonFlags | html.HrefTargetBlank,
+		Title:          title,
+		RenderNodeHook: renderHookCodeBlock,
+	}
+	renderer := html.NewRenderer(opts)
+
+	return RenderedContent{
+		HTML: string(markdown.Render(doc, renderer)),
+		TOC:  buildTOC(doc, tocOpts),
+	}
+}
+
+// parseMarkdown parses content with the extensions used across the blog
+func parseMarkdown(content string) ast.Node {
+	// Create markdown parser with extensions
+	extensions := parser.CommonExtensions | parser.AutoHeadingIDs | parser.NoEmptyLineBeforeBlock
+	p := parser.NewWithExtensions(extensions)
+
+	return p.Parse([]byte(content))
+}
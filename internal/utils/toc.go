@@ -0,0 +1,142 @@
+// internal/utils/toc.go
+package utils
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+type TOCEntry struct {
+	ID       string     `json:"id"`
+	Level    int        `json:"level"`
+	Title    string     `json:"title"`
+	Children []TOCEntry `json:"children,omitempty"`
+}
+
+// TOCOptions controls which heading levels appear in a table of contents
+type TOCOptions struct {
+	MinLevel int
+	MaxLevel int
+}
+
+// DefaultTOCOptions includes every heading level
+var DefaultTOCOptions = TOCOptions{MinLevel: 1, MaxLevel: 6}
+
+// normalize clamps the levels to valid heading levels, falling back to the defaults
+func (o TOCOptions) normalize() TOCOptions {
+	if o.MinLevel < 1 || o.MinLevel > 6 {
+		o.MinLevel = DefaultTOCOptions.MinLevel
+	}
+	if o.MaxLevel < o.MinLevel || o.MaxLevel > 6 {
+		o.MaxLevel = DefaultTOCOptions.MaxLevel
+	}
+	return o
+}
+
+// GenerateTableOfContents parses markdown content and builds a nested table of contents
+func GenerateTableOfContents(content string, opts TOCOptions) []TOCEntry {
+	doc := parseMarkdown(content)
+	uniqueHeadingIDs(doc)
+	return buildTOC(doc, opts)
+}
+
+// uniqueHeadingIDs makes every heading ID in the document unique, including explicit {#id} ones,
+// so the rendered anchors and the table of contents links always agree
+func uniqueHeadingIDs(doc ast.Node) {
+	used := make(map[string]bool)
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		heading, ok := node.(*ast.Heading)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+
+		base := heading.HeadingID
+		if base == "" {
+			base = "section"
+		}
+
+		id := base
+		for n := 1; used[id]; n++ {
+			id = base + "-" + strconv.Itoa(n)
+		}
+		used[id] = true
+		heading.HeadingID = id
+
+		return ast.SkipChildren
+	})
+}
+
+// tocNode is a mutable tree node used while nesting entries
+type tocNode struct {
+	entry    TOCEntry
+	children []*tocNode
+}
+
+// buildTOC collects headings within the configured levels and nests them under their parents
+func buildTOC(doc ast.Node, opts TOCOptions) []TOCEntry {
+	opts = opts.normalize()
+
+	root := &tocNode{}
+	stack := []*tocNode{root}
+
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		heading, ok := node.(*ast.Heading)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		if heading.IsTitleblock || heading.Level < opts.MinLevel || heading.Level > opts.MaxLevel {
+			return ast.SkipChildren
+		}
+
+		current := &tocNode{entry: TOCEntry{
+			ID:    heading.HeadingID,
+			Level: heading.Level,
+			Title: headingText(heading),
+		}}
+
+		// Pop back to the nearest heading with a lower level
+		for len(stack) > 1 && stack[len(stack)-1].entry.Level >= current.entry.Level {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1]
+		parent.children = append(parent.children, current)
+		stack = append(stack, current)
+
+		return ast.SkipChildren
+	})
+
+	return flattenTOC(root.children)
+}
+
+func flattenTOC(nodes []*tocNode) []TOCEntry {
+	if len(nodes) == 0 {
+		return nil
+	}
+	entries := make([]TOCEntry, 0, len(nodes))
+	for _, n := range nodes {
+		entry := n.entry
+		entry.Children = flattenTOC(n.children)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// headingText returns the plain text of a heading, without any inline markup
+func headingText(heading *ast.Heading) string {
+	var sb strings.Builder
+	ast.WalkFunc(heading, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *ast.Text:
+			sb.Write(n.Literal)
+		case *ast.Code:
+			sb.Write(n.Literal)
+		}
+		return ast.GoToNext
+	})
+	return strings.TrimSpace(sb.String())
+}
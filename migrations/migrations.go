@@ -0,0 +1,27 @@
+// migrations/migrations.go
+
+// Package migrations embeds the SQL migrations in the binary, so they are found
+// whatever directory the server is started from. Each database driver has its own
+// directory of migrations with matching versions. Each version has an up file and,
+// optionally, a down file that reverses it:
+//
+//	sqlite/000003_add_rendered_content.up.sql
+//	sqlite/000003_add_rendered_content.down.sql
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+//go:embed sqlite/*.sql postgres/*.sql
+var files embed.FS
+
+// For returns the migrations for a database driver
+func For(driver string) (fs.FS, error) {
+	if info, err := fs.Stat(files, driver); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("no migrations for database driver %q", driver)
+	}
+	return fs.Sub(files, driver)
+}
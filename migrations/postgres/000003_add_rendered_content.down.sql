@@ -0,0 +1,4 @@
+-- migrations/postgres/000003_add_rendered_content.down.sql
+ALTER TABLE posts DROP COLUMN reading_time;
+ALTER TABLE posts DROP COLUMN toc;
+ALTER TABLE posts DROP COLUMN content_html;
@@ -0,0 +1,2 @@
+-- migrations/postgres/000004_add_post_hide_toc.down.sql
+ALTER TABLE posts DROP COLUMN hide_toc;
@@ -0,0 +1,9 @@
+-- migrations/postgres/000005_create_posts_fts.down.sql
+DROP TRIGGER IF EXISTS tags_search ON tags;
+DROP TRIGGER IF EXISTS post_tags_search ON post_tags;
+DROP FUNCTION IF EXISTS tags_search_trigger();
+DROP FUNCTION IF EXISTS post_tags_search_trigger();
+DROP FUNCTION IF EXISTS refresh_post_search_tags(BIGINT);
+DROP INDEX IF EXISTS idx_posts_search_vector;
+ALTER TABLE posts DROP COLUMN search_vector;
+ALTER TABLE posts DROP COLUMN search_tags;
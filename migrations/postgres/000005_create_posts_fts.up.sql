@@ -0,0 +1,53 @@
+-- migrations/postgres/000005_create_posts_fts.up.sql
+-- Full-text search over a generated tsvector, weighted like the SQLite index: title,
+-- then description, then tags, then content. Tag names are copied into search_tags
+-- by triggers so they can be part of the generated column.
+ALTER TABLE posts ADD COLUMN search_tags TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE posts ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', title), 'A') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'B') ||
+    setweight(to_tsvector('english', search_tags), 'C') ||
+    setweight(to_tsvector('english', content), 'D')
+) STORED;
+
+CREATE INDEX idx_posts_search_vector ON posts USING GIN (search_vector);
+
+CREATE FUNCTION refresh_post_search_tags(target BIGINT) RETURNS void AS $$
+    UPDATE posts
+    SET search_tags = COALESCE((
+        SELECT string_agg(t.name, ' ')
+        FROM post_tags pt
+        JOIN tags t ON t.id = pt.tag_id
+        WHERE pt.post_id = target
+    ), '')
+    WHERE id = target;
+$$ LANGUAGE sql;
+
+-- Index the tags of the posts that already exist
+SELECT refresh_post_search_tags(id) FROM posts;
+
+-- Keep the tag names in sync with post_tags and tags
+CREATE FUNCTION post_tags_search_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM refresh_post_search_tags(OLD.post_id);
+    ELSE
+        PERFORM refresh_post_search_tags(NEW.post_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER post_tags_search AFTER INSERT OR DELETE ON post_tags
+FOR EACH ROW EXECUTE FUNCTION post_tags_search_trigger();
+
+CREATE FUNCTION tags_search_trigger() RETURNS trigger AS $$
+BEGIN
+    PERFORM refresh_post_search_tags(pt.post_id) FROM post_tags pt WHERE pt.tag_id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tags_search AFTER UPDATE OF name ON tags
+FOR EACH ROW EXECUTE FUNCTION tags_search_trigger();
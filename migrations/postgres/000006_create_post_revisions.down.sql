@@ -0,0 +1,2 @@
+-- migrations/postgres/000006_create_post_revisions.down.sql
+DROP TABLE IF EXISTS post_revisions;
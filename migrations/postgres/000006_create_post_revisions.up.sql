@@ -0,0 +1,20 @@
+-- migrations/postgres/000006_create_post_revisions.up.sql
+CREATE TABLE IF NOT EXISTS post_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    post_id BIGINT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    slug TEXT NOT NULL,
+    content TEXT NOT NULL,
+    description TEXT,
+    cover_image TEXT,
+    author TEXT NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_post_revisions_post_id ON post_revisions(post_id);
+
+-- Seed each existing post with its current state as the first revision
+INSERT INTO post_revisions (post_id, title, slug, content, description, cover_image, note, created_at)
+SELECT id, title, slug, content, description, cover_image, 'Initial revision', updated_at
+FROM posts;
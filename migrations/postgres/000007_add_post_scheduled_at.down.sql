@@ -0,0 +1,3 @@
+-- migrations/postgres/000007_add_post_scheduled_at.down.sql
+DROP INDEX IF EXISTS idx_posts_scheduled_at;
+ALTER TABLE posts DROP COLUMN scheduled_at;
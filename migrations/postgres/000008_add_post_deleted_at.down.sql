@@ -0,0 +1,3 @@
+-- migrations/postgres/000008_add_post_deleted_at.down.sql
+DROP INDEX IF EXISTS idx_posts_deleted_at;
+ALTER TABLE posts DROP COLUMN deleted_at;
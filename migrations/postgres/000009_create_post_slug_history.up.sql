@@ -0,0 +1,9 @@
+-- migrations/postgres/000009_create_post_slug_history.up.sql
+-- Former slugs of each post, so old URLs can redirect to the current one
+CREATE TABLE IF NOT EXISTS post_slug_history (
+    slug TEXT PRIMARY KEY,
+    post_id BIGINT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_post_slug_history_post_id ON post_slug_history(post_id);
@@ -0,0 +1,10 @@
+-- migrations/postgres/000010_create_post_preview_links.up.sql
+-- Share links that let anyone holding a signed token view an unpublished post
+CREATE TABLE IF NOT EXISTS post_preview_links (
+    id TEXT PRIMARY KEY,
+    post_id BIGINT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_post_preview_links_post_id ON post_preview_links(post_id);
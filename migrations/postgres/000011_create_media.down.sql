@@ -0,0 +1,2 @@
+-- migrations/postgres/000011_create_media.down.sql
+DROP TABLE IF EXISTS media;
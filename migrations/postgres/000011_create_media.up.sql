@@ -0,0 +1,16 @@
+-- migrations/postgres/000011_create_media.up.sql
+-- Uploaded images. Resized and WebP variants are listed as JSON in variants.
+CREATE TABLE IF NOT EXISTS media (
+    id BIGSERIAL PRIMARY KEY,
+    filename TEXT NOT NULL,
+    path TEXT NOT NULL UNIQUE,
+    content_type TEXT NOT NULL,
+    width INTEGER NOT NULL DEFAULT 0,
+    height INTEGER NOT NULL DEFAULT 0,
+    size BIGINT NOT NULL DEFAULT 0,
+    alt_text TEXT NOT NULL DEFAULT '',
+    variants TEXT NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_media_created_at ON media(created_at);
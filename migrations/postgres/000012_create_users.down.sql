@@ -0,0 +1,2 @@
+-- migrations/postgres/000012_create_users.down.sql
+DROP TABLE IF EXISTS users;
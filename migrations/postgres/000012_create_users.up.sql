@@ -0,0 +1,13 @@
+-- migrations/postgres/000012_create_users.up.sql
+-- Admin accounts. Passwords are stored as bcrypt hashes.
+CREATE TABLE IF NOT EXISTS users (
+    id BIGSERIAL PRIMARY KEY,
+    username TEXT NOT NULL,
+    password_hash TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'admin',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Usernames are unique regardless of case, as in SQLite
+CREATE UNIQUE INDEX idx_users_username ON users (LOWER(username));
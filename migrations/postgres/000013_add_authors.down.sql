@@ -0,0 +1,6 @@
+-- migrations/postgres/000013_add_authors.down.sql
+ALTER TABLE posts DROP COLUMN author_id;
+
+ALTER TABLE users DROP COLUMN avatar;
+ALTER TABLE users DROP COLUMN bio;
+ALTER TABLE users DROP COLUMN display_name;
@@ -0,0 +1,5 @@
+-- migrations/postgres/000014_add_two_factor.down.sql
+DROP TABLE IF EXISTS user_recovery_codes;
+
+ALTER TABLE users DROP COLUMN totp_last_step;
+ALTER TABLE users DROP COLUMN totp_secret;
@@ -0,0 +1,15 @@
+-- migrations/postgres/000014_add_two_factor.up.sql
+-- Optional TOTP second factor. totp_secret is empty until the user enrolls, and
+-- totp_last_step is the time step of the last accepted code, so no code works twice.
+ALTER TABLE users ADD COLUMN totp_secret TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0;
+
+-- Single-use recovery codes for when the authenticator is lost, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, code_hash)
+);
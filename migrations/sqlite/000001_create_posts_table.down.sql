@@ -0,0 +1,2 @@
+-- migrations/000001_create_posts_table.down.sql
+DROP TABLE IF EXISTS posts;
@@ -0,0 +1,3 @@
+-- migrations/000002_create_tags_table.down.sql
+DROP TABLE IF EXISTS post_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,4 @@
+-- migrations/000003_add_rendered_content.up.sql
+ALTER TABLE posts ADD COLUMN content_html TEXT NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN toc TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE posts ADD COLUMN reading_time INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- migrations/000004_add_post_hide_toc.up.sql
+ALTER TABLE posts ADD COLUMN hide_toc BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,8 @@
+-- migrations/000005_create_posts_fts.down.sql
+DROP TRIGGER IF EXISTS tags_fts_after_update;
+DROP TRIGGER IF EXISTS post_tags_fts_after_delete;
+DROP TRIGGER IF EXISTS post_tags_fts_after_insert;
+DROP TRIGGER IF EXISTS posts_fts_after_delete;
+DROP TRIGGER IF EXISTS posts_fts_after_update;
+DROP TRIGGER IF EXISTS posts_fts_after_insert;
+DROP TABLE IF EXISTS posts_fts;
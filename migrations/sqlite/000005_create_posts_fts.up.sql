@@ -0,0 +1,82 @@
+-- migrations/000005_create_posts_fts.up.sql
+-- Requires SQLite built with FTS5 (go build -tags sqlite_fts5)
+CREATE VIRTUAL TABLE IF NOT EXISTS posts_fts USING fts5(
+    title,
+    description,
+    content,
+    tags,
+    tokenize = 'porter unicode61 remove_diacritics 2'
+);
+
+-- Index the posts that already exist
+INSERT INTO posts_fts (rowid, title, description, content, tags)
+SELECT
+    p.id,
+    p.title,
+    COALESCE(p.description, ''),
+    p.content,
+    COALESCE((
+        SELECT group_concat(t.name, ' ')
+        FROM post_tags pt
+        JOIN tags t ON t.id = pt.tag_id
+        WHERE pt.post_id = p.id
+    ), '')
+FROM posts p;
+
+-- Keep the index in sync with posts
+CREATE TRIGGER IF NOT EXISTS posts_fts_after_insert AFTER INSERT ON posts
+BEGIN
+    INSERT INTO posts_fts (rowid, title, description, content, tags)
+    VALUES (new.id, new.title, COALESCE(new.description, ''), new.content, '');
+END;
+
+CREATE TRIGGER IF NOT EXISTS posts_fts_after_update AFTER UPDATE OF title, description, content ON posts
+BEGIN
+    UPDATE posts_fts
+    SET title = new.title,
+        description = COALESCE(new.description, ''),
+        content = new.content
+    WHERE rowid = new.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS posts_fts_after_delete AFTER DELETE ON posts
+BEGIN
+    DELETE FROM posts_fts WHERE rowid = old.id;
+END;
+
+-- Keep the tag names in sync with post_tags and tags
+CREATE TRIGGER IF NOT EXISTS post_tags_fts_after_insert AFTER INSERT ON post_tags
+BEGIN
+    UPDATE posts_fts
+    SET tags = COALESCE((
+        SELECT group_concat(t.name, ' ')
+        FROM post_tags pt
+        JOIN tags t ON t.id = pt.tag_id
+        WHERE pt.post_id = new.post_id
+    ), '')
+    WHERE rowid = new.post_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS post_tags_fts_after_delete AFTER DELETE ON post_tags
+BEGIN
+    UPDATE posts_fts
+    SET tags = COALESCE((
+        SELECT group_concat(t.name, ' ')
+        FROM post_tags pt
+        JOIN tags t ON t.id = pt.tag_id
+        WHERE pt.post_id = old.post_id
+    ), '')
+    WHERE rowid = old.post_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS tags_fts_after_update AFTER UPDATE OF name ON tags
+BEGIN
+    UPDATE posts_fts
+    SET tags = COALESCE((
+        SELECT group_concat(t.name, ' ')
+        FROM post_tags pt
+        JOIN tags t ON t.id = pt.tag_id
+        WHERE pt.post_id = posts_fts.rowid
+    ), '')
+    WHERE rowid IN (SELECT post_id FROM post_tags WHERE tag_id = new.id);
+END;
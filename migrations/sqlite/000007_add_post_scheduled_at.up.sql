@@ -0,0 +1,4 @@
+-- migrations/000007_add_post_scheduled_at.up.sql
+ALTER TABLE posts ADD COLUMN scheduled_at TIMESTAMP;
+
+CREATE INDEX idx_posts_scheduled_at ON posts(scheduled_at) WHERE scheduled_at IS NOT NULL;
@@ -0,0 +1,5 @@
+-- migrations/000008_add_post_deleted_at.up.sql
+-- Deleted posts are moved to the trash by setting deleted_at, and purged later
+ALTER TABLE posts ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX idx_posts_deleted_at ON posts(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,2 @@
+-- migrations/000009_create_post_slug_history.down.sql
+DROP TABLE IF EXISTS post_slug_history;
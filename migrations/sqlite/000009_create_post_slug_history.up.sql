@@ -0,0 +1,10 @@
+-- migrations/000009_create_post_slug_history.up.sql
+-- Former slugs of each post, so old URLs can redirect to the current one
+CREATE TABLE IF NOT EXISTS post_slug_history (
+    slug TEXT PRIMARY KEY,
+    post_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_post_slug_history_post_id ON post_slug_history(post_id);
@@ -0,0 +1,2 @@
+-- migrations/000010_create_post_preview_links.down.sql
+DROP TABLE IF EXISTS post_preview_links;
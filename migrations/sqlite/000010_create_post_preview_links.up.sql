@@ -0,0 +1,11 @@
+-- migrations/000010_create_post_preview_links.up.sql
+-- Share links that let anyone holding a signed token view an unpublished post
+CREATE TABLE IF NOT EXISTS post_preview_links (
+    id TEXT PRIMARY KEY,
+    post_id INTEGER NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_post_preview_links_post_id ON post_preview_links(post_id);
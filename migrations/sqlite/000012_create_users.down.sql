@@ -0,0 +1,2 @@
+-- migrations/sqlite/000012_create_users.down.sql
+DROP TABLE IF EXISTS users;
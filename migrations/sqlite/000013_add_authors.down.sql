@@ -0,0 +1,7 @@
+-- migrations/sqlite/000013_add_authors.down.sql
+DROP INDEX IF EXISTS idx_posts_author_id;
+ALTER TABLE posts DROP COLUMN author_id;
+
+ALTER TABLE users DROP COLUMN avatar;
+ALTER TABLE users DROP COLUMN bio;
+ALTER TABLE users DROP COLUMN display_name;
@@ -0,0 +1,12 @@
+-- migrations/sqlite/000013_add_authors.up.sql
+-- Public author profiles, and the author of each post
+ALTER TABLE users ADD COLUMN display_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN bio TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN avatar TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE posts ADD COLUMN author_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_posts_author_id ON posts(author_id);
+
+-- Credit existing posts to the first admin
+UPDATE posts SET author_id = (SELECT MIN(id) FROM users WHERE role = 'admin');
@@ -0,0 +1,15 @@
+-- migrations/sqlite/000014_add_two_factor.up.sql
+-- Optional TOTP second factor. totp_secret is empty until the user enrolls, and
+-- totp_last_step is the time step of the last accepted code, so no code works twice.
+ALTER TABLE users ADD COLUMN totp_secret TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN totp_last_step INTEGER NOT NULL DEFAULT 0;
+
+-- Single-use recovery codes for when the authenticator is lost, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, code_hash)
+);
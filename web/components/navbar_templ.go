@@ -1,6 +1,6 @@
 // Code generated by templ - DO NOT EDIT.
 
-// templ: version: v0.2.731
+// templ: version: v0.2.793
 // web/components/navbar.templ
 
 package components
@@ -17,6 +17,9 @@ type NavbarProps struct {
 func Navbar(props NavbarProps) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
 		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 		if !templ_7745c5c3_IsBuffer {
 			defer func() {
@@ -32,7 +35,7 @@ func Navbar(props NavbarProps) templ.Component {
 			templ_7745c5c3_Var1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<nav class=\"bg-gradient-to-r from-pastel-base to-pastel-warmGray border-b border-pastel-warmGray/50 dark:from-neutral-800 dark:to-neutral-900 dark:border-neutral-700\"><div class=\"container mx-auto px-4\"><div class=\"flex justify-between h-16\"><div class=\"flex\"><div class=\"flex-shrink-0 flex items-center\"><a href=\"/\" class=\"text-xl font-bold text-pastel-text dark:text-white font-mono\">Amogh's Eden</a></div><div class=\"hidden sm:ml-6 sm:flex sm:space-x-8\"><a href=\"/\" class=\"inline-flex items-center px-1 pt-1 text-sm font-medium text-pastel-text dark:text-white hover:text-neutral-800 dark:hover:text-white transition-colors duration-200\">Home</a> <a href=\"/blog\" class=\"inline-flex items-center px-1 pt-1 text-sm font-medium text-pastel-text/80 dark:text-neutral-400 hover:text-neutral-800 dark:hover:text-white transition-colors duration-200\">Blog</a> <a href=\"/blog\" class=\"inline-flex items-center px-1 pt-1 text-sm font-medium text-pastel-text/80 dark:text-neutral-400 hover:text-neutral-800 dark:hover:text-white transition-colors duration-200\">What I'm Currently Working on</a></div></div><div class=\"flex items-center space-x-4\">")
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<nav class=\"bg-gradient-to-r from-pastel-base to-pastel-warmGray border-b border-pastel-warmGray/50 dark:from-neutral-800 dark:to-neutral-900 dark:border-neutral-700\"><div class=\"container mx-auto px-4\"><div class=\"flex justify-between h-16\"><div class=\"flex\"><div class=\"flex-shrink-0 flex items-center\"><a href=\"/\" class=\"text-xl font-bold text-pastel-text dark:text-white font-mono\">Amogh's Eden</a></div><div class=\"hidden sm:ml-6 sm:flex sm:space-x-8\"><a href=\"/\" class=\"inline-flex items-center px-1 pt-1 text-sm font-medium text-pastel-text dark:text-white hover:text-neutral-800 dark:hover:text-white transition-colors duration-200\">Home</a> <a href=\"/blog\" class=\"inline-flex items-center px-1 pt-1 text-sm font-medium text-pastel-text/80 dark:text-neutral-400 hover:text-neutral-800 dark:hover:text-white transition-colors duration-200\">Blog</a> <a href=\"/blog\" class=\"inline-flex items-center px-1 pt-1 text-sm font-medium text-pastel-text/80 dark:text-neutral-400 hover:text-neutral-800 dark:hover:text-white transition-colors duration-200\">What I'm Currently Working on</a> <a href=\"/search\" class=\"inline-flex items-center px-1 pt-1 text-sm font-medium text-pastel-text/80 dark:text-neutral-400 hover:text-neutral-800 dark:hover:text-white transition-colors duration-200\">Search</a></div></div><div class=\"flex items-center space-x-4\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -49,3 +52,5 @@ func Navbar(props NavbarProps) templ.Component {
 		return templ_7745c5c3_Err
 	})
 }
+
+var _ = templruntime.GeneratedTemplate
@@ -1,6 +1,6 @@
 // Code generated by templ - DO NOT EDIT.
 
-// templ: version: v0.2.731
+// templ: version: v0.2.793
 // web/layouts/admin.templ
 
 package layouts
@@ -10,9 +10,26 @@ package layouts
 import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
+import (
+	"blog-portfolio/internal/middleware"
+	"blog-portfolio/internal/models"
+	"context"
+)
+
+// CurrentRole returns the signed in user's role, so pages can hide what it can't use
+func CurrentRole(ctx context.Context) string {
+	if user := middleware.GetUserFromContext(ctx); user != nil {
+		return user.Role
+	}
+	return ""
+}
+
 func Admin(data PageData) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
 		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 		if !templ_7745c5c3_IsBuffer {
 			defer func() {
@@ -35,7 +52,7 @@ func Admin(data PageData) templ.Component {
 		var templ_7745c5c3_Var2 string
 		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(data.Title)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/layouts/admin.templ`, Line: 10, Col: 22}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/layouts/admin.templ`, Line: 24, Col: 22}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
 		if templ_7745c5c3_Err != nil {
@@ -48,13 +65,23 @@ func Admin(data PageData) templ.Component {
 		var templ_7745c5c3_Var3 string
 		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(data.Description)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/layouts/admin.templ`, Line: 11, Col: 54}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/layouts/admin.templ`, Line: 25, Col: 54}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\"><link rel=\"stylesheet\" href=\"/static/css/main.css\"><link href=\"https://fonts.googleapis.com/css2?family=JetBrains+Mono:wght@400;500;600;700&amp;display=swap\" rel=\"stylesheet\"><script src=\"https://unpkg.com/htmx.org@1.9.10\"></script><script defer src=\"https://unpkg.com/alpinejs@3.13.5/dist/cdn.min.js\"></script></head><body class=\"h-full bg-neutral-50 dark:bg-neutral-900\"><div x-data=\"{ sidebarOpen: false }\" class=\"min-h-full\"><div x-show=\"sidebarOpen\" class=\"fixed inset-0 z-40 bg-neutral-600 bg-opacity-75 md:hidden\" x-transition:enter=\"transition-opacity ease-linear duration-300\" x-transition:enter-start=\"opacity-0\" x-transition:enter-end=\"opacity-100\" x-transition:leave=\"transition-opacity ease-linear duration-300\" x-transition:leave-start=\"opacity-100\" x-transition:leave-end=\"opacity-0\"></div><aside class=\"fixed inset-y-0 left-0 z-50 w-64 bg-neutral-800 transform transition-transform duration-300 md:translate-x-0\" :class=\"sidebarOpen ? &#39;translate-x-0&#39; : &#39;-translate-x-full&#39;\"><div class=\"flex items-center justify-between h-16 px-4 bg-neutral-900\"><a href=\"/admin/dashboard\" class=\"text-xl font-bold text-white\">Admin Panel</a> <button @click=\"sidebarOpen = false\" class=\"md:hidden text-neutral-400 hover:text-white\"><span class=\"sr-only\">Close sidebar</span> <svg class=\"h-6 w-6\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M6 18L18 6M6 6l12 12\"></path></svg></button></div><nav class=\"flex flex-col flex-1 p-4 space-y-1\"><a href=\"/admin/dashboard\" class=\"px-3 py-2 text-neutral-100 rounded-md hover:bg-neutral-700\">Dashboard</a> <a href=\"/admin/posts\" class=\"px-3 py-2 text-neutral-100 rounded-md hover:bg-neutral-700\">Blog Posts</a> <a href=\"/admin/posts/new/\" class=\"px-3 py-2 text-neutral-100 rounded-md hover:bg-neutral-700\">Create Post</a></nav></aside><div class=\"md:pl-64\"><div class=\"sticky top-0 z-10 bg-white dark:bg-neutral-900 shadow\"><div class=\"flex items-center justify-between h-16 px-4\"><button @click=\"sidebarOpen = true\" class=\"md:hidden text-neutral-500 hover:text-neutral-900 dark:text-neutral-400 dark:hover:text-white\"><span class=\"sr-only\">Open sidebar</span> <svg class=\"h-6 w-6\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M4 6h16M4 12h16M4 18h16\"></path></svg></button><div class=\"flex items-center\"><!-- <button --><!-- \ttype=\"button\" --><!-- \tx-data=\"{ darkMode: localStorage.theme === 'dark' }\" --><!-- \t@click=\"darkMode = !darkMode; localStorage.theme = darkMode ? 'dark' : 'light'; document.documentElement.classList.toggle('dark')\" --><!-- \tclass=\"p-2 text-neutral-500 hover:text-neutral-900 dark:text-neutral-400 dark:hover:text-white\" --><!-- > --><!-- \t<span x-show=\"!darkMode\" class=\"w-5 h-5\">🌙</span> --><!-- \t<span x-show=\"darkMode\" class=\"w-5 h-5\">☀️</span> --><!-- </button> --><a href=\"/\" target=\"blank\" class=\"ml-4 text-sm text-neutral-500 hover:text-neutral-900 dark:text-neutral-400 dark:hover:text-white\">View Site</a></div></div></div><main class=\"flex-1\">")
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\"><link rel=\"stylesheet\" href=\"/static/css/main.css\"><link rel=\"stylesheet\" href=\"/static/css/highlight.css\"><link href=\"https://fonts.googleapis.com/css2?family=JetBrains+Mono:wght@400;500;600;700&amp;display=swap\" rel=\"stylesheet\"><script src=\"https://unpkg.com/htmx.org@1.9.10\"></script><script defer src=\"https://unpkg.com/alpinejs@3.13.5/dist/cdn.min.js\"></script></head><body class=\"h-full bg-neutral-50 dark:bg-neutral-900\"><div x-data=\"{ sidebarOpen: false }\" class=\"min-h-full\"><div x-show=\"sidebarOpen\" class=\"fixed inset-0 z-40 bg-neutral-600 bg-opacity-75 md:hidden\" x-transition:enter=\"transition-opacity ease-linear duration-300\" x-transition:enter-start=\"opacity-0\" x-transition:enter-end=\"opacity-100\" x-transition:leave=\"transition-opacity ease-linear duration-300\" x-transition:leave-start=\"opacity-100\" x-transition:leave-end=\"opacity-0\"></div><aside class=\"fixed inset-y-0 left-0 z-50 w-64 bg-neutral-800 transform transition-transform duration-300 md:translate-x-0\" :class=\"sidebarOpen ? &#39;translate-x-0&#39; : &#39;-translate-x-full&#39;\"><div class=\"flex items-center justify-between h-16 px-4 bg-neutral-900\"><a href=\"/admin/dashboard\" class=\"text-xl font-bold text-white\">Admin Panel</a> <button @click=\"sidebarOpen = false\" class=\"md:hidden text-neutral-400 hover:text-white\"><span class=\"sr-only\">Close sidebar</span> <svg class=\"h-6 w-6\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M6 18L18 6M6 6l12 12\"></path></svg></button></div><nav class=\"flex flex-col flex-1 p-4 space-y-1\"><a href=\"/admin/dashboard\" class=\"px-3 py-2 text-neutral-100 rounded-md hover:bg-neutral-700\">Dashboard</a> <a href=\"/admin/posts\" class=\"px-3 py-2 text-neutral-100 rounded-md hover:bg-neutral-700\">Blog Posts</a> <a href=\"/admin/posts/new/\" class=\"px-3 py-2 text-neutral-100 rounded-md hover:bg-neutral-700\">Create Post</a> <a href=\"/admin/media\" class=\"px-3 py-2 text-neutral-100 rounded-md hover:bg-neutral-700\">Media</a> <a href=\"/admin/trash\" class=\"px-3 py-2 text-neutral-100 rounded-md hover:bg-neutral-700\">Trash</a> ")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		if models.CanManageSite(CurrentRole(ctx)) {
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<a href=\"/admin/users\" class=\"px-3 py-2 text-neutral-100 rounded-md hover:bg-neutral-700\">Users</a> <a href=\"/admin/backups\" class=\"px-3 py-2 text-neutral-100 rounded-md hover:bg-neutral-700\">Backups</a>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("</nav></aside><div class=\"md:pl-64\"><div class=\"sticky top-0 z-10 bg-white dark:bg-neutral-900 shadow\"><div class=\"flex items-center justify-between h-16 px-4\"><button @click=\"sidebarOpen = true\" class=\"md:hidden text-neutral-500 hover:text-neutral-900 dark:text-neutral-400 dark:hover:text-white\"><span class=\"sr-only\">Open sidebar</span> <svg class=\"h-6 w-6\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M4 6h16M4 12h16M4 18h16\"></path></svg></button><div class=\"flex items-center\"><!-- <button --><!-- \ttype=\"button\" --><!-- \tx-data=\"{ darkMode: localStorage.theme === 'dark' }\" --><!-- \t@click=\"darkMode = !darkMode; localStorage.theme = darkMode ? 'dark' : 'light'; document.documentElement.classList.toggle('dark')\" --><!-- \tclass=\"p-2 text-neutral-500 hover:text-neutral-900 dark:text-neutral-400 dark:hover:text-white\" --><!-- > --><!-- \t<span x-show=\"!darkMode\" class=\"w-5 h-5\">🌙</span> --><!-- \t<span x-show=\"darkMode\" class=\"w-5 h-5\">☀️</span> --><!-- </button> --><a href=\"/\" target=\"blank\" class=\"ml-4 text-sm text-neutral-500 hover:text-neutral-900 dark:text-neutral-400 dark:hover:text-white\">View Site</a> <a href=\"/admin/account/profile\" class=\"ml-4 text-sm text-neutral-500 hover:text-neutral-900 dark:text-neutral-400 dark:hover:text-white\">Profile</a> <a href=\"/admin/account/2fa\" class=\"ml-4 text-sm text-neutral-500 hover:text-neutral-900 dark:text-neutral-400 dark:hover:text-white\">Two-Factor</a> <a href=\"/admin/account/password\" class=\"ml-4 text-sm text-neutral-500 hover:text-neutral-900 dark:text-neutral-400 dark:hover:text-white\">Change Password</a> <a href=\"/logout\" class=\"ml-4 text-sm text-neutral-500 hover:text-neutral-900 dark:text-neutral-400 dark:hover:text-white\">Log Out</a></div></div></div><main class=\"flex-1\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -69,3 +96,5 @@ func Admin(data PageData) templ.Component {
 		return templ_7745c5c3_Err
 	})
 }
+
+var _ = templruntime.GeneratedTemplate
@@ -1,6 +1,6 @@
 // Code generated by templ - DO NOT EDIT.
 
-// templ: version: v0.2.731
+// templ: version: v0.2.793
 // web/pages/admin/editor.templ
 
 package admin
@@ -14,19 +14,25 @@ import (
 	"blog-portfolio/internal/models"
 	"blog-portfolio/web/layouts"
 	"fmt"
+	"time"
 )
 
 // PostEditorData holds all the data needed for the post editor
 type PostEditorData struct {
-	Post  *models.Post // Can be nil for new posts
-	Tags  []models.Tag // All available tags
-	IsNew bool         // True if creating new post
-	Error string       // Any error message to display
+	Post         *models.Post         // Can be nil for new posts
+	Tags         []models.Tag         // All available tags
+	IsNew        bool                 // True if creating new post
+	Error        string               // Any error message to display
+	PreviewLinks []models.PreviewLink // Active share links for an unpublished post
+	CanPublish   bool                 // False for contributors, who can only save drafts
 }
 
 func PostEditor(data PostEditorData) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
 		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
 		if !templ_7745c5c3_IsBuffer {
 			defer func() {
@@ -69,7 +75,36 @@ func PostEditor(data PostEditorData) templ.Component {
 					return templ_7745c5c3_Err
 				}
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("</h2></div><div class=\"mt-4 flex md:mt-0 md:ml-4\"><button type=\"button\" onclick=\"previewPost()\" class=\"mr-2 inline-flex items-center px-4 py-2 border border-neutral-300 dark:border-neutral-600 rounded-md shadow-sm text-sm font-medium text-neutral-700 dark:text-neutral-200 bg-white dark:bg-neutral-800 hover:bg-neutral-50 dark:hover:bg-neutral-700 focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-primary-500\">Preview</button> <button type=\"submit\" form=\"post-form\" name=\"action\" value=\"draft\" class=\"inline-flex items-center px-4 py-2 border border-neutral-300 dark:border-neutral-600 rounded-md shadow-sm text-sm font-medium text-neutral-700 dark:text-neutral-200 bg-white dark:bg-neutral-800 hover:bg-neutral-50 dark:hover:bg-neutral-700 focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-primary-500\">Save as Draft</button> <button type=\"submit\" form=\"post-form\" name=\"action\" value=\"publish\" class=\"inline-flex items-center px-4 py-2 border border-transparent text-sm font-medium rounded-md text-white bg-primary-600 hover:bg-primary-700 focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-primary-500\">Publish</button></div><dialog id=\"previewModal\" class=\"w-full max-w-4xl p-4 rounded-lg shadow-xl dark:bg-neutral-800\"><div class=\"flex justify-between items-center mb-4\"><h3 class=\"text-lg font-medium text-neutral-900 dark:text-white\">Post Preview</h3><button onclick=\"window.previewModal.close()\" class=\"text-neutral-500 hover:text-neutral-700 dark:hover:text-neutral-300\"><span class=\"sr-only\">Close</span> <svg class=\"h-6 w-6\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M6 18L18 6M6 6l12 12\"></path></svg></button></div><div class=\"prose dark:prose-invert max-w-none\" id=\"previewContent\"></div></dialog></div>")
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("</h2></div><div class=\"mt-4 flex md:mt-0 md:ml-4\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if !data.IsNew {
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<a href=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var3 templ.SafeURL = templ.SafeURL(fmt.Sprintf("/admin/posts/%d/revisions", data.Post.ID))
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(string(templ_7745c5c3_Var3)))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\" class=\"mr-2 inline-flex items-center px-4 py-2 border border-neutral-300 dark:border-neutral-600 rounded-md shadow-sm text-sm font-medium text-neutral-700 dark:text-neutral-200 bg-white dark:bg-neutral-800 hover:bg-neutral-50 dark:hover:bg-neutral-700 focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-primary-500\">History</a> ")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<button type=\"button\" onclick=\"previewPost()\" class=\"mr-2 inline-flex items-center px-4 py-2 border border-neutral-300 dark:border-neutral-600 rounded-md shadow-sm text-sm font-medium text-neutral-700 dark:text-neutral-200 bg-white dark:bg-neutral-800 hover:bg-neutral-50 dark:hover:bg-neutral-700 focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-primary-500\">Preview</button> <button type=\"submit\" form=\"post-form\" name=\"action\" value=\"draft\" class=\"inline-flex items-center px-4 py-2 border border-neutral-300 dark:border-neutral-600 rounded-md shadow-sm text-sm font-medium text-neutral-700 dark:text-neutral-200 bg-white dark:bg-neutral-800 hover:bg-neutral-50 dark:hover:bg-neutral-700 focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-primary-500\">Save as Draft</button> ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if data.CanPublish {
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<button type=\"submit\" form=\"post-form\" name=\"action\" value=\"schedule\" class=\"ml-2 inline-flex items-center px-4 py-2 border border-primary-600 rounded-md shadow-sm text-sm font-medium text-primary-700 dark:text-primary-300 bg-white dark:bg-neutral-800 hover:bg-primary-50 dark:hover:bg-neutral-700 focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-primary-500\">Schedule</button> <button type=\"submit\" form=\"post-form\" name=\"action\" value=\"publish\" class=\"inline-flex items-center px-4 py-2 border border-transparent text-sm font-medium rounded-md text-white bg-primary-600 hover:bg-primary-700 focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-primary-500\">Publish</button>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("</div><dialog id=\"previewModal\" class=\"w-full max-w-4xl p-4 rounded-lg shadow-xl dark:bg-neutral-800\"><div class=\"flex justify-between items-center mb-4\"><h3 class=\"text-lg font-medium text-neutral-900 dark:text-white\">Post Preview</h3><button onclick=\"window.previewModal.close()\" class=\"text-neutral-500 hover:text-neutral-700 dark:hover:text-neutral-300\"><span class=\"sr-only\">Close</span> <svg class=\"h-6 w-6\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M6 18L18 6M6 6l12 12\"></path></svg></button></div><div class=\"prose dark:prose-invert max-w-none\" id=\"previewContent\"></div></dialog> <dialog id=\"mediaModal\" class=\"w-full max-w-5xl p-4 rounded-lg shadow-xl dark:bg-neutral-800\"><div class=\"flex justify-between items-center\"><h3 class=\"text-lg font-medium text-neutral-900 dark:text-white\">Media Library</h3><button onclick=\"window.mediaModal.close()\" class=\"text-neutral-500 hover:text-neutral-700 dark:hover:text-neutral-300\"><span class=\"sr-only\">Close</span> <svg class=\"h-6 w-6\" fill=\"none\" viewBox=\"0 0 24 24\" stroke=\"currentColor\"><path stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M6 18L18 6M6 6l12 12\"></path></svg></button></div><div id=\"mediaPickerContent\"></div></dialog></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -78,12 +113,12 @@ func PostEditor(data PostEditorData) templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				var templ_7745c5c3_Var3 string
-				templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(data.Error)
+				var templ_7745c5c3_Var4 string
+				templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(data.Error)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 101, Col: 20}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 138, Col: 20}
 				}
-				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -96,8 +131,8 @@ func PostEditor(data PostEditorData) templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var4 templ.SafeURL = templ.SafeURL(getFormAction(data))
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(string(templ_7745c5c3_Var4)))
+			var templ_7745c5c3_Var5 templ.SafeURL = templ.SafeURL(getFormAction(data))
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(string(templ_7745c5c3_Var5)))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -115,26 +150,39 @@ func PostEditor(data PostEditorData) templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var5 string
-			templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(getPostTitle(data))
+			var templ_7745c5c3_Var6 string
+			templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(getPostTitle(data))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 159, Col: 34}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\" class=\"shadow-sm focus:ring-primary-500 focus:border-primary-500 block w-full sm:text-sm border-neutral-300 dark:border-neutral-600 rounded-md dark:bg-neutral-800 dark:text-white\" placeholder=\"Post title\"></div></div><div><label for=\"slug\" class=\"block text-sm font-medium text-neutral-700 dark:text-neutral-300\">URL Slug</label><div class=\"mt-1 flex rounded-md shadow-sm\"><span class=\"inline-flex items-center px-3 rounded-l-md border border-r-0 border-neutral-300 dark:border-neutral-600 bg-neutral-50 dark:bg-neutral-700 text-neutral-500 dark:text-neutral-300 sm:text-sm\">/blog/</span> <input type=\"text\" name=\"slug\" id=\"slug\" value=\"")
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 122, Col: 34}
+				return templ_7745c5c3_Err
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
+			var templ_7745c5c3_Var7 string
+			templ_7745c5c3_Var7, templ_7745c5c3_Err = templ.JoinStringErrs(getPostSlug(data))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 177, Col: 33}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var7))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\" class=\"shadow-sm focus:ring-primary-500 focus:border-primary-500 block w-full sm:text-sm border-neutral-300 dark:border-neutral-600 rounded-md dark:bg-neutral-800 dark:text-white\" placeholder=\"Post title\"></div></div><div><label for=\"description\" class=\"block text-sm font-medium text-neutral-700 dark:text-neutral-300\">Description</label><div class=\"mt-1\"><textarea id=\"description\" name=\"description\" rows=\"3\" class=\"shadow-sm focus:ring-primary-500 focus:border-primary-500 block w-full sm:text-sm border-neutral-300 dark:border-neutral-600 rounded-md dark:bg-neutral-800 dark:text-white\" placeholder=\"A brief description of your post\">")
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\" class=\"focus:ring-primary-500 focus:border-primary-500 block w-full sm:text-sm border-neutral-300 dark:border-neutral-600 rounded-none rounded-r-md dark:bg-neutral-800 dark:text-white\" placeholder=\"generated-from-the-title\"></div><p class=\"mt-2 text-sm text-neutral-500 dark:text-neutral-400\">Leave blank to generate it from the title. Old URLs redirect here when the slug changes.</p></div><div><label for=\"description\" class=\"block text-sm font-medium text-neutral-700 dark:text-neutral-300\">Description</label><div class=\"mt-1\"><textarea id=\"description\" name=\"description\" rows=\"3\" class=\"shadow-sm focus:ring-primary-500 focus:border-primary-500 block w-full sm:text-sm border-neutral-300 dark:border-neutral-600 rounded-md dark:bg-neutral-800 dark:text-white\" placeholder=\"A brief description of your post\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 			if data.Post != nil {
-				var templ_7745c5c3_Var6 string
-				templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(data.Post.Description)
+				var templ_7745c5c3_Var8 string
+				templ_7745c5c3_Var8, templ_7745c5c3_Err = templ.JoinStringErrs(data.Post.Description)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 141, Col: 32}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 199, Col: 32}
 				}
-				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var8))
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -144,30 +192,63 @@ func PostEditor(data PostEditorData) templ.Component {
 				return templ_7745c5c3_Err
 			}
 			if data.Post != nil {
-				var templ_7745c5c3_Var7 string
-				templ_7745c5c3_Var7, templ_7745c5c3_Err = templ.JoinStringErrs(data.Post.Content)
+				var templ_7745c5c3_Var9 string
+				templ_7745c5c3_Var9, templ_7745c5c3_Err = templ.JoinStringErrs(data.Post.Content)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 160, Col: 28}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 218, Col: 28}
 				}
-				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var7))
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var9))
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("</textarea></div><p class=\"mt-2 text-sm text-neutral-500 dark:text-neutral-400\">Write your post content using Markdown formatting.</p></div><div><label for=\"cover_image\" class=\"block text-sm font-medium text-neutral-700 dark:text-neutral-300\">Cover Image URL</label><div class=\"mt-1\"><input type=\"url\" name=\"cover_image\" id=\"cover_image\" value=\"")
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("</textarea></div><div class=\"mt-2 flex items-center justify-between gap-4\"><p class=\"text-sm text-neutral-500 dark:text-neutral-400\">Write your post content using Markdown formatting.</p><button type=\"button\" onclick=\"openMediaPicker(&#39;content&#39;)\" class=\"inline-flex items-center px-3 py-1.5 border border-neutral-300 dark:border-neutral-600 rounded-md shadow-sm text-sm font-medium text-neutral-700 dark:text-neutral-200 bg-white dark:bg-neutral-800 hover:bg-neutral-50 dark:hover:bg-neutral-700 focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-primary-500\">Insert Image</button></div></div><div><label for=\"cover_image\" class=\"block text-sm font-medium text-neutral-700 dark:text-neutral-300\">Cover Image URL</label><div class=\"mt-1 flex gap-2\"><input type=\"text\" name=\"cover_image\" id=\"cover_image\" value=\"")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			var templ_7745c5c3_Var8 string
-			templ_7745c5c3_Var8, templ_7745c5c3_Err = templ.JoinStringErrs(getPostCoverImage(data))
+			var templ_7745c5c3_Var10 string
+			templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs(getPostCoverImage(data))
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 177, Col: 39}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 244, Col: 39}
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var8))
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var10))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\" class=\"shadow-sm focus:ring-primary-500 focus:border-primary-500 block w-full sm:text-sm border-neutral-300 dark:border-neutral-600 rounded-md dark:bg-neutral-800 dark:text-white\" placeholder=\"https://example.com/image.jpg\"></div></div><div><label class=\"block text-sm font-medium text-neutral-700 dark:text-neutral-300\">Tags</label><div class=\"mt-2 space-y-2\">")
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\" class=\"shadow-sm focus:ring-primary-500 focus:border-primary-500 block w-full sm:text-sm border-neutral-300 dark:border-neutral-600 rounded-md dark:bg-neutral-800 dark:text-white\" placeholder=\"https://example.com/image.jpg\"> <button type=\"button\" onclick=\"openMediaPicker(&#39;cover&#39;)\" class=\"inline-flex items-center px-3 py-1.5 border border-neutral-300 dark:border-neutral-600 rounded-md shadow-sm text-sm font-medium text-neutral-700 dark:text-neutral-200 bg-white dark:bg-neutral-800 hover:bg-neutral-50 dark:hover:bg-neutral-700 focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-primary-500\">Choose</button></div></div><div class=\"relative flex items-start\"><div class=\"flex items-center h-5\"><input id=\"hide_toc\" name=\"hide_toc\" type=\"checkbox\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if data.Post != nil && data.Post.HideTOC {
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(" checked")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(" class=\"focus:ring-primary-500 h-4 w-4 text-primary-600 border-neutral-300 dark:border-neutral-600 rounded\"></div><div class=\"ml-3 text-sm\"><label for=\"hide_toc\" class=\"text-neutral-700 dark:text-neutral-300\">Hide table of contents</label><p class=\"text-neutral-500 dark:text-neutral-400\">Useful for short posts with only a few headings.</p></div></div>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if data.CanPublish {
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<div><label for=\"publish_at\" class=\"block text-sm font-medium text-neutral-700 dark:text-neutral-300\">Publish at</label><div class=\"mt-1\"><input type=\"datetime-local\" name=\"publish_at\" id=\"publish_at\" data-scheduled-at=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var11 string
+				templ_7745c5c3_Var11, templ_7745c5c3_Err = templ.JoinStringErrs(getPostScheduledAt(data))
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 284, Col: 53}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var11))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\" class=\"shadow-sm focus:ring-primary-500 focus:border-primary-500 block sm:text-sm border-neutral-300 dark:border-neutral-600 rounded-md dark:bg-neutral-800 dark:text-white\"> <input type=\"hidden\" name=\"tz_offset\" id=\"tz_offset\"></div><p class=\"mt-2 text-sm text-neutral-500 dark:text-neutral-400\">Pick a time and use Schedule to publish the post automatically. Saving as a draft cancels the schedule.</p></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("<div><label class=\"block text-sm font-medium text-neutral-700 dark:text-neutral-300\">Tags</label><div class=\"mt-2 space-y-2\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -176,12 +257,12 @@ func PostEditor(data PostEditorData) templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				var templ_7745c5c3_Var9 string
-				templ_7745c5c3_Var9, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("tag-%d", tag.ID))
+				var templ_7745c5c3_Var12 string
+				templ_7745c5c3_Var12, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("tag-%d", tag.ID))
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 192, Col: 45}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 303, Col: 45}
 				}
-				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var9))
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var12))
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -189,13 +270,13 @@ func PostEditor(data PostEditorData) templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				var templ_7745c5c3_Var10 string
-				templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("%d",
+				var templ_7745c5c3_Var13 string
+				templ_7745c5c3_Var13, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("%d",
 					tag.ID))
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 196, Col: 23}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 307, Col: 23}
 				}
-				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var10))
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var13))
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -213,12 +294,12 @@ func PostEditor(data PostEditorData) templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				var templ_7745c5c3_Var11 string
-				templ_7745c5c3_Var11, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("tag-%d", tag.ID))
+				var templ_7745c5c3_Var14 string
+				templ_7745c5c3_Var14, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("tag-%d", tag.ID))
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 202, Col: 52}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 313, Col: 52}
 				}
-				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var11))
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var14))
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -226,12 +307,12 @@ func PostEditor(data PostEditorData) templ.Component {
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				var templ_7745c5c3_Var12 string
-				templ_7745c5c3_Var12, templ_7745c5c3_Err = templ.JoinStringErrs(tag.Name)
+				var templ_7745c5c3_Var15 string
+				templ_7745c5c3_Var15, templ_7745c5c3_Err = templ.JoinStringErrs(tag.Name)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 203, Col: 21}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/pages/admin/editor.templ`, Line: 314, Col: 21}
 				}
-				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var12))
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var15))
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -240,7 +321,17 @@ func PostEditor(data PostEditorData) templ.Component {
 					return templ_7745c5c3_Err
 				}
 			}
-			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("</div></div></div></form></div><link rel=\"stylesheet\" href=\"https://unpkg.com/easymde/dist/easymde.min.css\"><script src=\"https://unpkg.com/easymde/dist/easymde.min.js\"></script>  <script>\n  const easyMDE = new EasyMDE({\n    element: document.getElementById('content'),\n    autofocus: true,\n    spellChecker: false,\n    toolbar: [\n      'bold', 'italic', 'heading', '|',\n      'code', 'quote', 'unordered-list', 'ordered-list', '|',\n      'link', 'image', '|',\n      'preview', 'side-by-side', 'fullscreen', '|',\n      'guide'\n    ],\n    status: ['autosave', 'lines', 'words', 'cursor'],\n    theme: document.documentElement.classList.contains('dark') ? 'dark' : 'light',\n    minHeight: '400px',\n    placeholder: 'Write your content here...',\n    renderingConfig: {\n      singleLineBreaks: false,\n      codeSyntaxHighlighting: true,\n    }\n  });\n\n  // Handle dark mode toggle\n  const observer = new MutationObserver((mutations) => {\n    mutations.forEach((mutation) => {\n      if (mutation.attributeName === 'class') {\n        const isDark = document.documentElement.classList.contains('dark');\n        easyMDE.updateTheme(isDark ? 'dark' : 'light');\n      }\n    });\n  });\n\n  observer.observe(document.documentElement, {\n    attributes: true\n  });\n\n  // Add custom styles for dark mode\n  const style = document.createElement('style');\n  style.textContent = `\n    .dark .EasyMDEContainer .CodeMirror {\n      background-color: rgb(38 38 38) !important;\n      color: #fff !important;\n      border-color: rgb(64 64 64) !important;\n    }\n    \n    .dark .editor-toolbar button {\n      color: #fff !important;\n    }\n    \n    .dark .editor-toolbar button:hover {\n      background-color: rgb(64 64 64) !important;\n    }\n\n    .dark .editor-toolbar {\n      border-color: rgb(64 64 64) !important;\n    }\n\n    .dark .EasyMDEContainer .CodeMirror-cursor {\n      border-color: #fff !important;\n    }\n\n    .dark .editor-preview {\n      background-color: rgb(38 38 38) !important;\n      color: #fff !important;\n    }\n\n    .dark .cm-s-easymde .CodeMirror-gutters {\n      background-color: rgb(38 38 38) !important;\n      border-right: 1px solid rgb(64 64 64) !important;\n    }\n\n    .dark .editor-toolbar.fullscreen {\n      background-color: rgb(38 38 38) !important;\n    }\n\n    .dark .editor-preview-side {\n      background-color: rgb(38 38 38) !important;\n    }\n  `;\n  document.head.appendChild(style);\n\n  function previewPost() {\n    // Get form data\n    const form = document.getElementById('post-form');\n\n    // Create a temporary form for the preview\n    const previewForm = document.createElement('form');\n    previewForm.method = 'POST';\n    previewForm.action = '/admin/preview';\n    previewForm.style.display = 'none';\n\n    // Add title\n    const titleInput = document.createElement('input');\n    titleInput.type = 'hidden';\n    titleInput.name = 'title';\n    titleInput.value = document.getElementById('title').value;\n    previewForm.appendChild(titleInput);\n\n    // Add description\n    const descInput = document.createElement('input');\n    descInput.type = 'hidden';\n    descInput.name = 'description';\n    descInput.value = document.getElementById('description').value;\n    previewForm.appendChild(descInput);\n\n    // Add cover image if it exists\n    const coverInput = document.createElement('input');\n    coverInput.type = 'hidden';\n    coverInput.name = 'cover_image';\n    coverInput.value = document.getElementById('cover_image').value;\n    previewForm.appendChild(coverInput);\n\n    // Add content from the editor\n    const contentInput = document.createElement('input');\n    contentInput.type = 'hidden';\n    contentInput.name = 'content';\n    contentInput.value = easyMDE.value();\n    previewForm.appendChild(contentInput);\n\n    // Add any selected tags\n    const selectedTags = document.querySelectorAll('input[name=\"tags[]\"]:checked');\n    selectedTags.forEach(tag => {\n      const tagInput = document.createElement('input');\n      tagInput.type = 'hidden';\n      tagInput.name = 'tags[]';\n      tagInput.value = tag.value;\n      previewForm.appendChild(tagInput);\n    });\n\n    // Submit form\n    document.body.appendChild(previewForm);\n    previewForm.submit();\n    document.body.removeChild(previewForm);\n  }\n\n</script>")
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("</div></div></div></form>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if !data.IsNew && !data.Post.Published {
+				templ_7745c5c3_Err = PreviewLinks(data.Post, data.PreviewLinks, "").Render(ctx, templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("</div><link rel=\"stylesheet\" href=\"https://unpkg.com/easymde/dist/easymde.min.css\"><script src=\"https://unpkg.com/easymde/dist/easymde.min.js\"></script>  <script>\n  const easyMDE = new EasyMDE({\n    element: document.getElementById('content'),\n    autofocus: true,\n    spellChecker: false,\n    toolbar: [\n      'bold', 'italic', 'heading', '|',\n      'code', 'quote', 'unordered-list', 'ordered-list', '|',\n      'link', 'image', '|',\n      'preview', 'side-by-side', 'fullscreen', '|',\n      'guide'\n    ],\n    status: ['autosave', 'lines', 'words', 'cursor'],\n    theme: document.documentElement.classList.contains('dark') ? 'dark' : 'light',\n    minHeight: '400px',\n    placeholder: 'Write your content here...',\n    renderingConfig: {\n      singleLineBreaks: false,\n      codeSyntaxHighlighting: true,\n    }\n  });\n\n  // Handle dark mode toggle\n  const observer = new MutationObserver((mutations) => {\n    mutations.forEach((mutation) => {\n      if (mutation.attributeName === 'class') {\n        const isDark = document.documentElement.classList.contains('dark');\n        easyMDE.updateTheme(isDark ? 'dark' : 'light');\n      }\n    });\n  });\n\n  observer.observe(document.documentElement, {\n    attributes: true\n  });\n\n  // Add custom styles for dark mode\n  const style = document.createElement('style');\n  style.textContent = `\n    .dark .EasyMDEContainer .CodeMirror {\n      background-color: rgb(38 38 38) !important;\n      color: #fff !important;\n      border-color: rgb(64 64 64) !important;\n    }\n    \n    .dark .editor-toolbar button {\n      color: #fff !important;\n    }\n    \n    .dark .editor-toolbar button:hover {\n      background-color: rgb(64 64 64) !important;\n    }\n\n    .dark .editor-toolbar {\n      border-color: rgb(64 64 64) !important;\n    }\n\n    .dark .EasyMDEContainer .CodeMirror-cursor {\n      border-color: #fff !important;\n    }\n\n    .dark .editor-preview {\n      background-color: rgb(38 38 38) !important;\n      color: #fff !important;\n    }\n\n    .dark .cm-s-easymde .CodeMirror-gutters {\n      background-color: rgb(38 38 38) !important;\n      border-right: 1px solid rgb(64 64 64) !important;\n    }\n\n    .dark .editor-toolbar.fullscreen {\n      background-color: rgb(38 38 38) !important;\n    }\n\n    .dark .editor-preview-side {\n      background-color: rgb(38 38 38) !important;\n    }\n  `;\n  document.head.appendChild(style);\n\n  // Show the pending schedule in the author's local time and send their offset on submit\n  // (contributors can't schedule, so they don't get the field)\n  const publishAt = document.getElementById('publish_at');\n  if (publishAt && publishAt.dataset.scheduledAt) {\n    const scheduled = new Date(publishAt.dataset.scheduledAt);\n    const local = new Date(scheduled.getTime() - scheduled.getTimezoneOffset() * 60000);\n    publishAt.value = local.toISOString().slice(0, 16);\n  }\n  document.getElementById('post-form').addEventListener('submit', () => {\n    if (!publishAt) {\n      return;\n    }\n    const value = publishAt.value ? new Date(publishAt.value) : new Date();\n    document.getElementById('tz_offset').value = value.getTimezoneOffset();\n  });\n\n  // Media picker: inserts the chosen image into the content or sets it as the cover\n  let mediaTarget = 'content';\n  function openMediaPicker(target) {\n    mediaTarget = target;\n    htmx.ajax('GET', '/admin/media/picker', '#mediaPickerContent');\n    window.mediaModal.showModal();\n  }\n  function pickMedia(button) {\n    if (mediaTarget === 'cover') {\n      document.getElementById('cover_image').value = button.dataset.url;\n    } else {\n      easyMDE.codemirror.replaceSelection(button.dataset.markdown);\n      easyMDE.codemirror.focus();\n    }\n    window.mediaModal.close();\n  }\n\n  function previewPost() {\n    // Get form data\n    const form = document.getElementById('post-form');\n\n    // Create a temporary form for the preview\n    const previewForm = document.createElement('form');\n    previewForm.method = 'POST';\n    previewForm.action = '/admin/preview';\n    previewForm.style.display = 'none';\n\n    // Add title\n    const titleInput = document.createElement('input');\n    titleInput.type = 'hidden';\n    titleInput.name = 'title';\n    titleInput.value = document.getElementById('title').value;\n    previewForm.appendChild(titleInput);\n\n    // Add description\n    const descInput = document.createElement('input');\n    descInput.type = 'hidden';\n    descInput.name = 'description';\n    descInput.value = document.getElementById('description').value;\n    previewForm.appendChild(descInput);\n\n    // Add cover image if it exists\n    const coverInput = document.createElement('input');\n    coverInput.type = 'hidden';\n    coverInput.name = 'cover_image';\n    coverInput.value = document.getElementById('cover_image').value;\n    previewForm.appendChild(coverInput);\n\n    // Add content from the editor\n    const contentInput = document.createElement('input');\n    contentInput.type = 'hidden';\n    contentInput.name = 'content';\n    contentInput.value = easyMDE.value();\n    previewForm.appendChild(contentInput);\n\n    // Add any selected tags\n    const selectedTags = document.querySelectorAll('input[name=\"tags[]\"]:checked');\n    selectedTags.forEach(tag => {\n      const tagInput = document.createElement('input');\n      tagInput.type = 'hidden';\n      tagInput.name = 'tags[]';\n      tagInput.value = tag.value;\n      previewForm.appendChild(tagInput);\n    });\n\n    // Submit form\n    document.body.appendChild(previewForm);\n    previewForm.submit();\n    document.body.removeChild(previewForm);\n  }\n\n</script>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -279,6 +370,13 @@ func getPostTitle(data PostEditorData) string {
 	return ""
 }
 
+func getPostSlug(data PostEditorData) string {
+	if data.Post != nil {
+		return data.Post.Slug
+	}
+	return ""
+}
+
 func getPostCoverImage(data PostEditorData) string {
 	if data.Post != nil {
 		return data.Post.CoverImage
@@ -286,6 +384,14 @@ func getPostCoverImage(data PostEditorData) string {
 	return ""
 }
 
+// getPostScheduledAt returns the pending publish time in RFC 3339 for the editor script
+func getPostScheduledAt(data PostEditorData) string {
+	if data.Post != nil && data.Post.ScheduledAt != nil {
+		return data.Post.ScheduledAt.Format(time.RFC3339)
+	}
+	return ""
+}
+
 // hasTag checks if a post has a specific tag
 func hasTag(postTags []models.Tag, tag models.Tag) bool {
 	for _, t := range postTags {
@@ -295,3 +401,5 @@ func hasTag(postTags []models.Tag, tag models.Tag) bool {
 	}
 	return false
 }
+
+var _ = templruntime.GeneratedTemplate